@@ -0,0 +1,242 @@
+package genainormalizerprocessor
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Bedrock instrumentations record the model id and, optionally, the JSON
+// request and response bodies of InvokeModel and Converse calls. The body
+// shape depends on the model family.
+// See: https://docs.aws.amazon.com/bedrock/latest/userguide/model-parameters.html
+const (
+	bedrockProvider     = "aws.bedrock"
+	bedrockModelIDKey   = "aws.bedrock.model_id"
+	bedrockRequestBody  = "aws.bedrock.request.body"
+	bedrockResponseBody = "aws.bedrock.response.body"
+	bedrockRPCService   = "BedrockRuntime"
+)
+
+// bedrockMappings covers the scalar Bedrock attributes.
+var bedrockMappings = map[string]string{
+	bedrockModelIDKey:                    "gen_ai.request.model",
+	"aws.bedrock.request.temperature":    "gen_ai.request.temperature",
+	"aws.bedrock.request.top_p":          "gen_ai.request.top_p",
+	"aws.bedrock.request.max_tokens":     "gen_ai.request.max_tokens",
+	"aws.bedrock.usage.input_tokens":     "gen_ai.usage.input_tokens",
+	"aws.bedrock.usage.output_tokens":    "gen_ai.usage.output_tokens",
+	"aws.bedrock.response.stop_reason":   "gen_ai.response.finish_reasons",
+	"aws.bedrock.response.finish_reason": "gen_ai.response.finish_reasons",
+}
+
+// bedrockBody lists the fields extracted from the request and response bodies
+// of one model family.
+type bedrockBody struct {
+	request  []bodyField
+	response []bodyField
+}
+
+// bedrockConverse is the shape of the Converse API, shared by every model
+// family, and of the InvokeModel bodies of Amazon Nova.
+var bedrockConverse = bedrockBody{
+	request: []bodyField{
+		{"modelId", "gen_ai.request.model"},
+		{"inferenceConfig.temperature", "gen_ai.request.temperature"},
+		{"inferenceConfig.topP", "gen_ai.request.top_p"},
+		{"inferenceConfig.topK", "gen_ai.request.top_k"},
+		{"inferenceConfig.maxTokens", "gen_ai.request.max_tokens"},
+		{"inferenceConfig.max_new_tokens", "gen_ai.request.max_tokens"},
+		{"inferenceConfig.stopSequences", "gen_ai.request.stop_sequences"},
+	},
+	response: []bodyField{
+		{"usage.inputTokens", "gen_ai.usage.input_tokens"},
+		{"usage.outputTokens", "gen_ai.usage.output_tokens"},
+		{"usage.totalTokens", "gen_ai.usage.total_tokens"},
+		{"usage.cacheReadInputTokens", "gen_ai.usage.cache_read.input_tokens"},
+		{"usage.cacheWriteInputTokens", "gen_ai.usage.cache_creation.input_tokens"},
+		{"stopReason", "gen_ai.response.finish_reasons"},
+	},
+}
+
+// bedrockFamilies maps a model family, the vendor part of the model id, to
+// its InvokeModel body shape.
+var bedrockFamilies = map[string]bedrockBody{
+	"anthropic": {
+		request: []bodyField{
+			{"temperature", "gen_ai.request.temperature"},
+			{"top_p", "gen_ai.request.top_p"},
+			{"top_k", "gen_ai.request.top_k"},
+			{"max_tokens", "gen_ai.request.max_tokens"},
+			{"max_tokens_to_sample", "gen_ai.request.max_tokens"},
+			{"stop_sequences", "gen_ai.request.stop_sequences"},
+		},
+		response: []bodyField{
+			{"id", "gen_ai.response.id"},
+			{"model", "gen_ai.response.model"},
+			{"usage.input_tokens", "gen_ai.usage.input_tokens"},
+			{"usage.output_tokens", "gen_ai.usage.output_tokens"},
+			{"usage.cache_read_input_tokens", "gen_ai.usage.cache_read.input_tokens"},
+			{"usage.cache_creation_input_tokens", "gen_ai.usage.cache_creation.input_tokens"},
+			{"stop_reason", "gen_ai.response.finish_reasons"},
+		},
+	},
+	"amazon.titan": {
+		request: []bodyField{
+			{"textGenerationConfig.temperature", "gen_ai.request.temperature"},
+			{"textGenerationConfig.topP", "gen_ai.request.top_p"},
+			{"textGenerationConfig.maxTokenCount", "gen_ai.request.max_tokens"},
+			{"textGenerationConfig.stopSequences", "gen_ai.request.stop_sequences"},
+		},
+		response: []bodyField{
+			{"inputTextTokenCount", "gen_ai.usage.input_tokens"},
+			{"results.0.tokenCount", "gen_ai.usage.output_tokens"},
+			{"results.*.completionReason", "gen_ai.response.finish_reasons"},
+		},
+	},
+	"meta": {
+		request: []bodyField{
+			{"temperature", "gen_ai.request.temperature"},
+			{"top_p", "gen_ai.request.top_p"},
+			{"max_gen_len", "gen_ai.request.max_tokens"},
+		},
+		response: []bodyField{
+			{"prompt_token_count", "gen_ai.usage.input_tokens"},
+			{"generation_token_count", "gen_ai.usage.output_tokens"},
+			{"stop_reason", "gen_ai.response.finish_reasons"},
+		},
+	},
+	"cohere": {
+		request: []bodyField{
+			{"temperature", "gen_ai.request.temperature"},
+			{"p", "gen_ai.request.top_p"},
+			{"k", "gen_ai.request.top_k"},
+			{"max_tokens", "gen_ai.request.max_tokens"},
+			{"stop_sequences", "gen_ai.request.stop_sequences"},
+		},
+		response: []bodyField{
+			{"response_id", "gen_ai.response.id"},
+			{"id", "gen_ai.response.id"},
+			{"meta.billed_units.input_tokens", "gen_ai.usage.input_tokens"},
+			{"meta.billed_units.output_tokens", "gen_ai.usage.output_tokens"},
+			{"finish_reason", "gen_ai.response.finish_reasons"},
+			{"generations.*.finish_reason", "gen_ai.response.finish_reasons"},
+		},
+	},
+	"mistral": {
+		request: []bodyField{
+			{"temperature", "gen_ai.request.temperature"},
+			{"top_p", "gen_ai.request.top_p"},
+			{"top_k", "gen_ai.request.top_k"},
+			{"max_tokens", "gen_ai.request.max_tokens"},
+			{"stop", "gen_ai.request.stop_sequences"},
+		},
+		response: []bodyField{
+			{"usage.prompt_tokens", "gen_ai.usage.input_tokens"},
+			{"usage.completion_tokens", "gen_ai.usage.output_tokens"},
+			{"outputs.*.stop_reason", "gen_ai.response.finish_reasons"},
+			{"choices.*.finish_reason", "gen_ai.response.finish_reasons"},
+		},
+	},
+}
+
+// normalizeBedrock extracts gen_ai.* attributes from the Bedrock attributes
+// and JSON bodies of attrs, if any.
+func (n *normalizer) normalizeBedrock(attrs pcommon.Map) {
+	if !isBedrock(attrs) {
+		return
+	}
+
+	req := decodeBody(attrs, bedrockRequestBody)
+	resp := decodeBody(attrs, bedrockResponseBody)
+
+	modelID := ""
+	for _, k := range []string{bedrockModelIDKey, "gen_ai.request.model"} {
+		if v, ok := attrs.Get(k); ok && v.AsString() != "" {
+			modelID = v.AsString()
+			break
+		}
+	}
+
+	if modelID == "" {
+		// Converse request bodies carry the model id; it is extracted below.
+		if id, ok := lookupPath(req, "modelId").(string); ok {
+			modelID = id
+		}
+	}
+
+	// Extracted values never replace mapped or existing keys within one
+	// record; written tracks the keys set from the bodies, and those the
+	// scalar attributes of the record map to, which run after this step.
+	written := map[string]bool{}
+	for src, dst := range bedrockMappings {
+		if _, ok := attrs.Get(src); ok {
+			written[dst] = true
+		}
+	}
+	if family, ok := bedrockFamilies[bedrockFamily(modelID)]; ok {
+		n.extractFields(attrs, req, family.request, written)
+		n.extractFields(attrs, resp, family.response, written)
+	}
+	n.extractFields(attrs, req, bedrockConverse.request, written)
+	n.extractFields(attrs, resp, bedrockConverse.response, written)
+
+	n.put(attrs, "gen_ai.provider.name", pcommon.NewValueStr(bedrockProvider))
+	operation := "chat"
+	if strings.Contains(modelID, "embed") {
+		operation = "embeddings"
+	}
+	if _, exists := attrs.Get("gen_ai.operation.name"); !exists {
+		attrs.PutStr("gen_ai.operation.name", operation)
+	}
+
+	var bodies []string
+	if req != nil {
+		bodies = append(bodies, bedrockRequestBody)
+	}
+	if resp != nil {
+		bodies = append(bodies, bedrockResponseBody)
+	}
+	n.dropKeys(attrs, bodies)
+}
+
+// isBedrock reports whether attrs describe a Bedrock inference call: a
+// BedrockRuntime RPC, or a record carrying a model id or a request or
+// response body. Other Bedrock calls, such as knowledge base retrievals or
+// agent invocations, are left alone.
+func isBedrock(attrs pcommon.Map) bool {
+	if v, ok := attrs.Get("rpc.service"); ok && v.Str() == bedrockRPCService {
+		return true
+	}
+	for _, k := range []string{bedrockModelIDKey, bedrockRequestBody, bedrockResponseBody} {
+		if _, ok := attrs.Get(k); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bedrockFamily returns the model family of a Bedrock model id or inference
+// profile ARN, e.g. "anthropic" for "us.anthropic.claude-3-haiku-20240307-v1:0"
+// or "amazon.titan" for "amazon.titan-text-express-v1".
+func bedrockFamily(modelID string) string {
+	if i := strings.LastIndex(modelID, "/"); i >= 0 {
+		modelID = modelID[i+1:]
+	}
+	parts := strings.Split(modelID, ".")
+	for i, p := range parts {
+		if i+1 == len(parts) {
+			break
+		}
+		if p == "amazon" {
+			if strings.HasPrefix(parts[i+1], "titan") {
+				return "amazon.titan"
+			}
+			return "amazon"
+		}
+		if _, ok := bedrockFamilies[p]; ok {
+			return p
+		}
+	}
+	return ""
+}
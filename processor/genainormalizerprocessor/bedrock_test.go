@@ -0,0 +1,221 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestBedrockFamily(t *testing.T) {
+	tests := map[string]string{
+		"anthropic.claude-3-sonnet-20240229-v1:0":                                      "anthropic",
+		"us.anthropic.claude-3-haiku-20240307-v1:0":                                    "anthropic",
+		"arn:aws:bedrock:us-east-1:123456789012:inference-profile/eu.meta.llama3-2-1b": "meta",
+		"amazon.titan-text-express-v1":                                                 "amazon.titan",
+		"amazon.nova-pro-v1:0":                                                         "amazon",
+		"cohere.command-r-v1:0":                                                        "cohere",
+		"mistral.mistral-large-2402-v1:0":                                              "mistral",
+		"gpt-4o":                                                                       "",
+	}
+	for id, want := range tests {
+		if got := bedrockFamily(id); got != want {
+			t.Errorf("bedrockFamily(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func consumeSpan(t *testing.T, cfg *Config, attrs map[string]any) pcommon.Map {
+	t.Helper()
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	if err := sp.Attributes().FromRaw(attrs); err != nil {
+		t.Fatalf("invalid attributes: %v", err)
+	}
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	return sp.Attributes()
+}
+
+func TestBedrockInvokeModelBodies(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]any
+		want  map[string]any
+	}{
+		{
+			name: "anthropic",
+			attrs: map[string]any{
+				"aws.bedrock.model_id":      "anthropic.claude-3-sonnet-20240229-v1:0",
+				"aws.bedrock.request.body":  `{"anthropic_version":"bedrock-2023-05-31","max_tokens":512,"temperature":0.2,"stop_sequences":["\n\nHuman:"]}`,
+				"aws.bedrock.response.body": `{"id":"msg_1","usage":{"input_tokens":21,"output_tokens":7},"stop_reason":"end_turn"}`,
+			},
+			want: map[string]any{
+				"gen_ai.request.model":           "anthropic.claude-3-sonnet-20240229-v1:0",
+				"gen_ai.request.max_tokens":      int64(512),
+				"gen_ai.request.temperature":     0.2,
+				"gen_ai.request.stop_sequences":  []any{"\n\nHuman:"},
+				"gen_ai.response.id":             "msg_1",
+				"gen_ai.usage.input_tokens":      int64(21),
+				"gen_ai.usage.output_tokens":     int64(7),
+				"gen_ai.response.finish_reasons": []any{"end_turn"},
+			},
+		},
+		{
+			name: "titan",
+			attrs: map[string]any{
+				"aws.bedrock.model_id":      "amazon.titan-text-express-v1",
+				"aws.bedrock.request.body":  `{"inputText":"hi","textGenerationConfig":{"maxTokenCount":100,"topP":0.9}}`,
+				"aws.bedrock.response.body": `{"inputTextTokenCount":3,"results":[{"tokenCount":12,"completionReason":"FINISH"}]}`,
+			},
+			want: map[string]any{
+				"gen_ai.request.max_tokens":      int64(100),
+				"gen_ai.request.top_p":           0.9,
+				"gen_ai.usage.input_tokens":      int64(3),
+				"gen_ai.usage.output_tokens":     int64(12),
+				"gen_ai.response.finish_reasons": []any{"FINISH"},
+			},
+		},
+		{
+			name: "llama",
+			attrs: map[string]any{
+				"aws.bedrock.model_id":      "meta.llama3-8b-instruct-v1:0",
+				"aws.bedrock.request.body":  `{"prompt":"hi","max_gen_len":64,"temperature":0.5}`,
+				"aws.bedrock.response.body": `{"prompt_token_count":4,"generation_token_count":9,"stop_reason":"stop"}`,
+			},
+			want: map[string]any{
+				"gen_ai.request.max_tokens":      int64(64),
+				"gen_ai.usage.input_tokens":      int64(4),
+				"gen_ai.usage.output_tokens":     int64(9),
+				"gen_ai.response.finish_reasons": []any{"stop"},
+			},
+		},
+		{
+			name: "cohere",
+			attrs: map[string]any{
+				"aws.bedrock.model_id":      "cohere.command-text-v14",
+				"aws.bedrock.request.body":  `{"prompt":"hi","p":0.75,"k":5,"max_tokens":20}`,
+				"aws.bedrock.response.body": `{"id":"gen_1","generations":[{"finish_reason":"COMPLETE"},{"finish_reason":"MAX_TOKENS"}]}`,
+			},
+			want: map[string]any{
+				"gen_ai.request.top_p":           0.75,
+				"gen_ai.request.top_k":           5.0,
+				"gen_ai.request.max_tokens":      int64(20),
+				"gen_ai.response.id":             "gen_1",
+				"gen_ai.response.finish_reasons": []any{"COMPLETE", "MAX_TOKENS"},
+			},
+		},
+		{
+			name: "converse",
+			attrs: map[string]any{
+				"rpc.service":               "BedrockRuntime",
+				"aws.bedrock.request.body":  `{"modelId":"amazon.nova-lite-v1:0","inferenceConfig":{"maxTokens":256,"temperature":0.7}}`,
+				"aws.bedrock.response.body": `{"usage":{"inputTokens":10,"outputTokens":20,"totalTokens":30},"stopReason":"end_turn"}`,
+			},
+			want: map[string]any{
+				"gen_ai.request.model":           "amazon.nova-lite-v1:0",
+				"gen_ai.request.max_tokens":      int64(256),
+				"gen_ai.request.temperature":     0.7,
+				"gen_ai.usage.total_tokens":      int64(30),
+				"gen_ai.response.finish_reasons": []any{"end_turn"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig()
+			cfg.Profiles = []string{ProfileBedrock}
+			attrs := consumeSpan(t, cfg, tt.attrs)
+
+			assertHasStr(t, attrs, "gen_ai.provider.name", "aws.bedrock")
+			assertHasStr(t, attrs, "gen_ai.operation.name", "chat")
+			for k, want := range tt.want {
+				got, ok := attrs.Get(k)
+				if !ok || !reflect.DeepEqual(got.AsRaw(), want) {
+					t.Errorf("%s = %#v, want %#v", k, got.AsRaw(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBedrockScalarsAndDropOriginal(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileBedrock}
+	cfg.DropOriginal = true
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"aws.bedrock.model_id":           "amazon.titan-embed-text-v2:0",
+		"aws.bedrock.usage.input_tokens": "8",
+		"aws.bedrock.request.body":       `{"inputText":"hi"}`,
+	})
+
+	want := map[string]any{
+		"gen_ai.request.model":      "amazon.titan-embed-text-v2:0",
+		"gen_ai.provider.name":      "aws.bedrock",
+		"gen_ai.operation.name":     "embeddings",
+		"gen_ai.usage.input_tokens": int64(8),
+	}
+	if got := attrs.AsRaw(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected attributes %v", got)
+	}
+}
+
+func TestBedrockScalarsWinOverBodies(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileBedrock}
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"aws.bedrock.model_id":            "anthropic.claude-3-sonnet-20240229-v1:0",
+		"aws.bedrock.request.temperature": 0.5,
+		"aws.bedrock.request.body":        `{"temperature":0.2,"max_tokens":512}`,
+	})
+
+	assertRaw(t, attrs, "gen_ai.request.temperature", 0.5)
+	assertRaw(t, attrs, "gen_ai.request.max_tokens", int64(512))
+}
+
+func assertRaw(t *testing.T, attrs pcommon.Map, key string, want any) {
+	t.Helper()
+	v, ok := attrs.Get(key)
+	if !ok || !reflect.DeepEqual(v.AsRaw(), want) {
+		t.Fatalf("expected %s=%v, got %v", key, want, attrs.AsRaw())
+	}
+}
+
+func TestBedrockIgnoresOtherSpans(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileBedrock}
+	attrs := consumeSpan(t, cfg, map[string]any{"rpc.service": "S3"})
+	if _, ok := attrs.Get("gen_ai.provider.name"); ok {
+		t.Fatalf("expected non-Bedrock span to be untouched")
+	}
+}
+
+func TestBedrockIgnoresRetrievalAndAgentSpans(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileBedrock}
+	for _, span := range []map[string]any{
+		{"rpc.service": "BedrockAgentRuntime", "aws.bedrock.knowledge_base.id": "KB12345"},
+		{"rpc.service": "BedrockAgentRuntime", "aws.bedrock.agent.id": "AGENT1"},
+	} {
+		attrs := consumeSpan(t, cfg, span)
+		for _, key := range []string{"gen_ai.provider.name", "gen_ai.operation.name"} {
+			if _, ok := attrs.Get(key); ok {
+				t.Fatalf("expected %s not to be set, got %v", key, attrs.AsRaw())
+			}
+		}
+	}
+}
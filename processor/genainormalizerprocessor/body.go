@@ -0,0 +1,94 @@
+package genainormalizerprocessor
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// bodyField extracts the value at a dot-separated path of a JSON body into a
+// gen_ai.* key. A "*" path segment collects the values of every array
+// element.
+type bodyField struct {
+	path string
+	dst  string
+}
+
+// extractFields writes the fields present in body to attrs. Destinations in
+// written are skipped, and the written ones are added to it, so the first
+// field found for a destination wins.
+func (n *normalizer) extractFields(attrs pcommon.Map, body any, fields []bodyField, written map[string]bool) {
+	for _, f := range fields {
+		if written[f.dst] {
+			continue
+		}
+		raw := lookupPath(body, f.path)
+		if raw == nil {
+			continue
+		}
+		val := pcommon.NewValueEmpty()
+		if err := val.FromRaw(raw); err != nil {
+			continue
+		}
+		n.put(attrs, f.dst, val)
+		written[f.dst] = true
+	}
+}
+
+// decodeBody returns the JSON object stored as a string under key, or nil.
+func decodeBody(attrs pcommon.Map, key string) any {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return nil
+	}
+	if v.Type() == pcommon.ValueTypeMap {
+		return v.Map().AsRaw()
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(v.AsString()), &body); err != nil {
+		return nil
+	}
+	return body
+}
+
+// lookupPath returns the value at the dot-separated path of body, or nil.
+func lookupPath(body any, path string) any {
+	if body == nil {
+		return nil
+	}
+	head, rest, more := strings.Cut(path, ".")
+	var next any
+	switch v := body.(type) {
+	case map[string]any:
+		next = v[head]
+	case []any:
+		if head == "*" {
+			var all []any
+			for _, e := range v {
+				if more {
+					e = lookupPath(e, rest)
+				}
+				if e != nil {
+					all = append(all, e)
+				}
+			}
+			if len(all) == 0 {
+				return nil
+			}
+			return all
+		}
+		idx, err := strconv.Atoi(head)
+		if err != nil || idx < 0 || idx >= len(v) {
+			return nil
+		}
+		next = v[idx]
+	default:
+		return nil
+	}
+	if !more {
+		return next
+	}
+	return lookupPath(next, rest)
+}
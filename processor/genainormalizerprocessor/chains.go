@@ -0,0 +1,199 @@
+package genainormalizerprocessor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// mappingGraph is the graph of exact source -> destination mappings. A
+// source may have several destinations when tables disagree.
+type mappingGraph map[string][]string
+
+func (g mappingGraph) add(src, dst string) {
+	if isPattern(src) || src == dst {
+		return
+	}
+	for _, d := range g[src] {
+		if d == dst {
+			return
+		}
+	}
+	g[src] = append(g[src], dst)
+	sort.Strings(g[src])
+}
+
+// cycles returns every cycle of g once, each starting at its smallest key,
+// e.g. [a b] for a -> b -> a.
+func (g mappingGraph) cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	seen := map[string]bool{}
+	var path []string
+	var found [][]string
+
+	var visit func(key string)
+	visit = func(key string) {
+		state[key] = visiting
+		path = append(path, key)
+		for _, next := range g[key] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				i := len(path) - 1
+				for path[i] != next {
+					i--
+				}
+				cycle := rotateToMin(append([]string(nil), path[i:]...))
+				if id := strings.Join(cycle, "\x00"); !seen[id] {
+					seen[id] = true
+					found = append(found, cycle)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = done
+	}
+	for _, key := range sortedKeys(g) {
+		if state[key] == unvisited {
+			visit(key)
+		}
+	}
+	return found
+}
+
+// chains returns the acyclic paths of more than one hop, e.g. [a b c] for
+// a -> b -> c. Each path starts at a key that is not itself a destination.
+func (g mappingGraph) chains() [][]string {
+	isDst := map[string]bool{}
+	for _, dsts := range g {
+		for _, d := range dsts {
+			isDst[d] = true
+		}
+	}
+	var found [][]string
+	var walk func(path []string)
+	walk = func(path []string) {
+		extended := false
+		for _, next := range g[path[len(path)-1]] {
+			if contains(path, next) {
+				continue
+			}
+			extended = true
+			walk(append(path, next))
+		}
+		if !extended && len(path) > 2 {
+			found = append(found, append([]string(nil), path...))
+		}
+	}
+	for _, src := range sortedKeys(g) {
+		if !isDst[src] {
+			walk([]string{src})
+		}
+	}
+	return found
+}
+
+func rotateToMin(cycle []string) []string {
+	min := 0
+	for i, k := range cycle {
+		if k < cycle[min] {
+			min = i
+		}
+	}
+	return append(cycle[min:], cycle[:min]...)
+}
+
+// formatPath renders a mapping path as "a -> b -> c".
+func formatPath(path []string) string {
+	return strings.Join(path, " -> ")
+}
+
+// resolveChains rewrites the entries of tables into a single-hop table: an
+// entry whose destination is itself mapped, as in a -> b -> c, is pointed at
+// the final key, so a maps to c whatever order the rules run in. The
+// intermediate key is not written. Each source follows the entry that wins
+// for it (earlier tables first), as long as that entry applies wherever the
+// source entry does; otherwise the intermediate key is written. An untyped
+// entry takes the first type declared along its chain. Cycles are rejected.
+// defaults are the targets of entries that declare none.
+func resolveChains(logger *zap.Logger, tables [][]mappingEntry, defaults []string) error {
+	winner := map[string]mappingEntry{}
+	g := mappingGraph{}
+	for _, table := range tables {
+		for _, e := range table {
+			if _, claimed := winner[e.src]; claimed || isPattern(e.src) {
+				continue
+			}
+			winner[e.src] = e
+			g.add(e.src, e.dst)
+		}
+	}
+	var errs []error
+	for _, cycle := range g.cycles() {
+		errs = append(errs, fmt.Errorf("mapping cycle %s", formatPath(append(cycle, cycle[0]))))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	for _, chain := range g.chains() {
+		logger.Warn("resolved chained mapping", zap.String("chain", formatPath(chain)))
+	}
+
+	for _, table := range tables {
+		for i, e := range table {
+			if strings.Contains(e.dst, "$") {
+				continue
+			}
+			for {
+				next, ok := winner[e.dst]
+				if !ok || next.dst == e.dst || !covers(next, e, defaults) {
+					break
+				}
+				if e.conv.typ == "" {
+					e.conv = next.conv
+				}
+				e.dst = next.dst
+			}
+			table[i] = e
+		}
+	}
+	return nil
+}
+
+// covers reports whether next applies to every target and record that e
+// applies to, so that a value mapped by e can skip straight past next.
+func covers(next, e mappingEntry, defaults []string) bool {
+	if len(next.guards) > 0 {
+		if len(e.guards) == 0 {
+			return false
+		}
+		for _, g := range e.guards {
+			if !slices.Contains(next.guards, g) {
+				return false
+			}
+		}
+	}
+	targets, nextTargets := e.targets, next.targets
+	if len(targets) == 0 {
+		targets = defaults
+	}
+	if len(nextTargets) == 0 {
+		nextTargets = defaults
+	}
+	for _, t := range targets {
+		if !slices.Contains(nextTargets, t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package genainormalizerprocessor
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMappingGraph(t *testing.T) {
+	g := mappingGraph{}
+	g.add("a", "b")
+	g.add("b", "c")
+	g.add("x", "y")
+	g.add("y", "z")
+	g.add("z", "y")
+	g.add("s", "s")
+	g.add("llm.*", "gen_ai.$1")
+
+	if got := g.cycles(); len(got) != 1 || formatPath(got[0]) != "y -> z" {
+		t.Fatalf("unexpected cycles %q", got)
+	}
+	if got := g.chains(); len(got) != 2 || formatPath(got[0]) != "a -> b -> c" || formatPath(got[1]) != "x -> y -> z" {
+		t.Fatalf("unexpected chains %q", got)
+	}
+}
+
+func TestChainedMappings(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileLLM}
+	cfg.Mappings = map[string]string{
+		"my.model":  "llm.model",
+		"my.tokens": "my.usage.input",
+	}
+	cfg.Rules = []MappingRule{{Source: "my.usage.input", Destination: "gen_ai.usage.input_tokens", Type: TypeInt}}
+
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"my.model":  "gpt-4o",
+		"my.tokens": "12",
+	})
+	assertHasStr(t, attrs, "gen_ai.request.model", "gpt-4o")
+	if v, ok := attrs.Get("gen_ai.usage.input_tokens"); !ok || v.Int() != 12 {
+		t.Fatalf("expected the chain to carry the rule type, got %v", attrs.AsRaw())
+	}
+	for _, key := range []string{"llm.model", "my.usage.input"} {
+		if _, ok := attrs.Get(key); ok {
+			t.Fatalf("expected intermediate key %s not to be written, got %v", key, attrs.AsRaw())
+		}
+	}
+}
+
+func TestMergedMappingCycle(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileLLM}
+	cfg.Mappings = map[string]string{"gen_ai.request.model": "llm.model"}
+	_, err := newNormalizer(zap.NewNop(), cfg, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "mapping cycle gen_ai.request.model -> llm.model -> gen_ai.request.model") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestChainStopsAtNarrowerHop(t *testing.T) {
+	tests := map[string]MappingRule{
+		"targets":   {Source: "b", Destination: "c", Targets: []string{TargetResource}},
+		"condition": {Source: "b", Destination: "c", Condition: Condition{ServiceName: "billing"}},
+	}
+	for name, next := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := createDefaultConfig()
+			cfg.EnableDefaults = false
+			cfg.Rules = []MappingRule{{Source: "a", Destination: "b", Targets: []string{TargetSpan}}, next}
+
+			attrs := consumeSpan(t, cfg, map[string]any{"a": "v"})
+			assertHasStr(t, attrs, "b", "v")
+			if _, ok := attrs.Get("c"); ok {
+				t.Fatalf("expected the narrower hop not to apply, got %v", attrs.AsRaw())
+			}
+		})
+	}
+}
+
+func TestMappingChainWarning(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Mappings = map[string]string{"my.model": "llm.model", "llm.model": "gen_ai.request.model"}
+	if _, err := newNormalizer(zap.New(core), cfg, nil, nil); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["chain"] != "my.model -> llm.model -> gen_ai.request.model" {
+		t.Fatalf("expected one chain warning, got %v", entries)
+	}
+}
@@ -0,0 +1,209 @@
+package genainormalizerprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Value types a mapping may coerce its destination to.
+const (
+	TypeString      = "string"
+	TypeInt         = "int"
+	TypeDouble      = "double"
+	TypeBool        = "bool"
+	TypeStringSlice = "string[]"
+	TypeIntSlice    = "int[]"
+	TypeDoubleSlice = "double[]"
+	TypeBoolSlice   = "bool[]"
+)
+
+// Actions taken when a value cannot be coerced to the declared type.
+const (
+	// OnFailureKeep writes the value unchanged (the default).
+	OnFailureKeep = "keep"
+	// OnFailureDrop leaves the destination unset.
+	OnFailureDrop = "drop"
+	// OnFailureErrorAttribute leaves the destination unset and records its
+	// key in coercionErrorsAttr.
+	OnFailureErrorAttribute = "error_attribute"
+)
+
+// coercionErrorsAttr lists the destination keys that were not written because
+// their value could not be coerced under OnFailureErrorAttribute.
+const coercionErrorsAttr = "genai_normalizer.coercion_errors"
+
+// converter coerces mapped values to a declared type. The zero value copies
+// values unchanged.
+type converter struct {
+	typ       string
+	onFailure string
+}
+
+func newConverter(typ, onFailure string) (converter, error) {
+	switch typ {
+	case "", TypeString, TypeInt, TypeDouble, TypeBool, TypeStringSlice, TypeIntSlice, TypeDoubleSlice, TypeBoolSlice:
+	default:
+		return converter{}, fmt.Errorf("unknown type %q", typ)
+	}
+	switch onFailure {
+	case "":
+		onFailure = OnFailureKeep
+	case OnFailureKeep, OnFailureDrop, OnFailureErrorAttribute:
+	default:
+		return converter{}, fmt.Errorf("unknown on_failure %q", onFailure)
+	}
+	return converter{typ: typ, onFailure: onFailure}, nil
+}
+
+// write stores src under dst in attrs, coercing it when a type is declared.
+func (c converter) write(attrs pcommon.Map, dst string, src pcommon.Value) {
+	if c.typ == "" {
+		src.CopyTo(attrs.PutEmpty(dst))
+		return
+	}
+
+	out := pcommon.NewValueEmpty()
+	if coerceValue(src, c.typ, out) {
+		out.CopyTo(attrs.PutEmpty(dst))
+		return
+	}
+
+	switch c.onFailure {
+	case OnFailureKeep:
+		src.CopyTo(attrs.PutEmpty(dst))
+	case OnFailureErrorAttribute:
+		appendStr(attrs, coercionErrorsAttr, dst)
+	}
+}
+
+// coerceValue converts v to typ into out and reports whether it succeeded.
+func coerceValue(v pcommon.Value, typ string, out pcommon.Value) bool {
+	if elem, ok := strings.CutSuffix(typ, "[]"); ok {
+		return coerceSlice(v, elem, out)
+	}
+
+	// A one-element slice is accepted where a scalar is expected.
+	if v.Type() == pcommon.ValueTypeSlice {
+		if v.Slice().Len() != 1 {
+			return false
+		}
+		v = v.Slice().At(0)
+	}
+
+	switch typ {
+	case TypeString:
+		switch v.Type() {
+		case pcommon.ValueTypeStr, pcommon.ValueTypeInt, pcommon.ValueTypeDouble, pcommon.ValueTypeBool:
+			out.SetStr(v.AsString())
+			return true
+		}
+	case TypeInt:
+		if i, ok := asInt(v); ok {
+			out.SetInt(i)
+			return true
+		}
+	case TypeDouble:
+		if f, ok := asDouble(v); ok {
+			out.SetDouble(f)
+			return true
+		}
+	case TypeBool:
+		if b, ok := asBool(v); ok {
+			out.SetBool(b)
+			return true
+		}
+	}
+	return false
+}
+
+func coerceSlice(v pcommon.Value, elem string, out pcommon.Value) bool {
+	in := pcommon.NewSlice()
+	switch v.Type() {
+	case pcommon.ValueTypeSlice:
+		in = v.Slice()
+	case pcommon.ValueTypeStr:
+		// Accept JSON-encoded arrays such as `["stop"]`.
+		var raw []any
+		if s := strings.TrimSpace(v.Str()); strings.HasPrefix(s, "[") && json.Unmarshal([]byte(s), &raw) == nil {
+			if err := in.FromRaw(raw); err != nil {
+				return false
+			}
+		} else {
+			v.CopyTo(in.AppendEmpty())
+		}
+	default:
+		v.CopyTo(in.AppendEmpty())
+	}
+
+	res := pcommon.NewSlice()
+	res.EnsureCapacity(in.Len())
+	for i := 0; i < in.Len(); i++ {
+		if in.At(i).Type() == pcommon.ValueTypeSlice || !coerceValue(in.At(i), elem, res.AppendEmpty()) {
+			return false
+		}
+	}
+	res.MoveAndAppendTo(out.SetEmptySlice())
+	return true
+}
+
+func asInt(v pcommon.Value) (int64, bool) {
+	switch v.Type() {
+	case pcommon.ValueTypeInt:
+		return v.Int(), true
+	case pcommon.ValueTypeDouble:
+		return doubleToInt(v.Double())
+	case pcommon.ValueTypeStr:
+		s := strings.TrimSpace(v.Str())
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return doubleToInt(f)
+		}
+	}
+	return 0, false
+}
+
+// doubleToInt accepts only integral values so that no precision is lost.
+func doubleToInt(f float64) (int64, bool) {
+	if f != math.Trunc(f) || math.IsInf(f, 0) || math.Abs(f) > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func asDouble(v pcommon.Value) (float64, bool) {
+	switch v.Type() {
+	case pcommon.ValueTypeDouble:
+		return v.Double(), true
+	case pcommon.ValueTypeInt:
+		return float64(v.Int()), true
+	case pcommon.ValueTypeStr:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.Str()), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func asBool(v pcommon.Value) (bool, bool) {
+	switch v.Type() {
+	case pcommon.ValueTypeBool:
+		return v.Bool(), true
+	case pcommon.ValueTypeInt:
+		switch v.Int() {
+		case 0:
+			return false, true
+		case 1:
+			return true, true
+		}
+	case pcommon.ValueTypeStr:
+		b, err := strconv.ParseBool(strings.TrimSpace(v.Str()))
+		return b, err == nil
+	}
+	return false, false
+}
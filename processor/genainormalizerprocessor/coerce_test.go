@@ -0,0 +1,110 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		in   any
+		typ  string
+		want any
+		ok   bool
+	}{
+		{in: "100", typ: TypeInt, want: int64(100), ok: true},
+		{in: "100.0", typ: TypeInt, want: int64(100), ok: true},
+		{in: 42.0, typ: TypeInt, want: int64(42), ok: true},
+		{in: 42.5, typ: TypeInt, ok: false},
+		{in: "abc", typ: TypeInt, ok: false},
+		{in: "0.7", typ: TypeDouble, want: 0.7, ok: true},
+		{in: int64(1), typ: TypeDouble, want: 1.0, ok: true},
+		{in: "true", typ: TypeBool, want: true, ok: true},
+		{in: int64(0), typ: TypeBool, want: false, ok: true},
+		{in: int64(7), typ: TypeString, want: "7", ok: true},
+		{in: []any{"x"}, typ: TypeString, want: "x", ok: true},
+		{in: []any{"x", "y"}, typ: TypeString, ok: false},
+		{in: "stop", typ: TypeStringSlice, want: []any{"stop"}, ok: true},
+		{in: `["stop","length"]`, typ: TypeStringSlice, want: []any{"stop", "length"}, ok: true},
+		{in: []any{"1", int64(2)}, typ: TypeIntSlice, want: []any{int64(1), int64(2)}, ok: true},
+		{in: []any{"1", "x"}, typ: TypeIntSlice, ok: false},
+	}
+
+	for _, tt := range tests {
+		in := pcommon.NewValueEmpty()
+		if err := in.FromRaw(tt.in); err != nil {
+			t.Fatalf("FromRaw(%v): %v", tt.in, err)
+		}
+		out := pcommon.NewValueEmpty()
+		ok := coerceValue(in, tt.typ, out)
+		if ok != tt.ok {
+			t.Fatalf("coerce %v to %s: got ok=%v want %v", tt.in, tt.typ, ok, tt.ok)
+		}
+		if ok && !reflect.DeepEqual(out.AsRaw(), tt.want) {
+			t.Fatalf("coerce %v to %s: got %#v want %#v", tt.in, tt.typ, out.AsRaw(), tt.want)
+		}
+	}
+}
+
+func TestRulesCoercion(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Rules = []MappingRule{
+		{Source: "llm.usage.prompt_tokens", Destination: "gen_ai.usage.input_tokens", Type: TypeInt},
+		{Source: "llm.request.temperature", Destination: "gen_ai.request.temperature", Type: TypeDouble, OnFailure: OnFailureDrop},
+		{Source: "llm.request.top_p", Destination: "gen_ai.request.top_p", Type: TypeDouble, OnFailure: OnFailureErrorAttribute},
+		{Source: "anthropic.stop_reason", Destination: "gen_ai.response.finish_reasons", Type: TypeStringSlice},
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("llm.usage.prompt_tokens", "100")
+	sp.Attributes().PutStr("llm.request.temperature", "warm")
+	sp.Attributes().PutStr("llm.request.top_p", "high")
+	sp.Attributes().PutStr("anthropic.stop_reason", "end_turn")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	attrs := sp.Attributes()
+	if v, ok := attrs.Get("gen_ai.usage.input_tokens"); !ok || v.Type() != pcommon.ValueTypeInt || v.Int() != 100 {
+		t.Fatalf("expected int gen_ai.usage.input_tokens=100, got %v", v.AsRaw())
+	}
+	if _, ok := attrs.Get("gen_ai.request.temperature"); ok {
+		t.Fatalf("expected gen_ai.request.temperature to be dropped")
+	}
+	if _, ok := attrs.Get("gen_ai.request.top_p"); ok {
+		t.Fatalf("expected gen_ai.request.top_p to be left unset")
+	}
+	if v, ok := attrs.Get(coercionErrorsAttr); !ok || !reflect.DeepEqual(v.AsRaw(), []any{"gen_ai.request.top_p"}) {
+		t.Fatalf("expected %s=[gen_ai.request.top_p], got %v", coercionErrorsAttr, v.AsRaw())
+	}
+	if v, ok := attrs.Get("gen_ai.response.finish_reasons"); !ok || !reflect.DeepEqual(v.AsRaw(), []any{"end_turn"}) {
+		t.Fatalf("expected gen_ai.response.finish_reasons=[end_turn], got %v", v.AsRaw())
+	}
+}
+
+func TestRulesInvalidType(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Rules = []MappingRule{{Source: "a", Destination: "gen_ai.b", Type: "float"}}
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for unknown type")
+	}
+}
@@ -0,0 +1,176 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// recordContext is what conditions can inspect besides the attributes being
+// normalized: the resource and scope of the record and, for spans and their
+// events and links, the span with its enclosing ScopeSpans and ResourceSpans
+// for OTTL. time is the time of the span or log record, used to pick prices.
+type recordContext struct {
+	resource      pcommon.Resource
+	scope         pcommon.InstrumentationScope
+	span          ptrace.Span
+	scopeSpans    ptrace.ScopeSpans
+	resourceSpans ptrace.ResourceSpans
+	hasSpan       bool
+	time          pcommon.Timestamp
+}
+
+// guard decides whether a guarded mapping entry or profile applies to a
+// record, before the record is modified.
+type guard func(attrs pcommon.Map, rc recordContext) bool
+
+// noScope stands in for the scope when normalizing resource attributes. It
+// is never modified.
+var noScope = pcommon.NewInstrumentationScope()
+
+// resourceContext returns the context of the attributes of resource itself.
+func resourceContext(resource pcommon.Resource) recordContext {
+	return recordContext{resource: resource, scope: noScope}
+}
+
+func (rc recordContext) withSpan(span ptrace.Span) recordContext {
+	rc.span = span
+	rc.hasSpan = true
+	rc.time = span.StartTimestamp()
+	return rc
+}
+
+var spanKinds = map[string]ptrace.SpanKind{
+	"internal": ptrace.SpanKindInternal,
+	"server":   ptrace.SpanKindServer,
+	"client":   ptrace.SpanKindClient,
+	"producer": ptrace.SpanKindProducer,
+	"consumer": ptrace.SpanKindConsumer,
+}
+
+// condition is a compiled Condition.
+type condition struct {
+	scopeName    string
+	scopeVersion string
+	serviceName  string
+	spanName     *regexp.Regexp
+	spanKinds    []ptrace.SpanKind
+	present      []string
+	where        *ottl.ConditionSequence[ottlspan.TransformContext]
+}
+
+// isZero reports whether c sets no criteria.
+func (c Condition) isZero() bool {
+	return c.ScopeName == "" && c.ScopeVersion == "" && c.ServiceName == "" &&
+		c.SpanName == "" && len(c.SpanKinds) == 0 && len(c.AttributesPresent) == 0 && c.Where == ""
+}
+
+// compileCondition returns nil for a condition without criteria. Errors
+// evaluating the where clause are logged to logger.
+func compileCondition(c Condition, logger *zap.Logger) (*condition, error) {
+	if c.isZero() {
+		return nil, nil
+	}
+	cc := &condition{
+		scopeName:    c.ScopeName,
+		scopeVersion: c.ScopeVersion,
+		serviceName:  c.ServiceName,
+		present:      c.AttributesPresent,
+	}
+	if c.SpanName != "" {
+		re, err := regexp.Compile(c.SpanName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid span_name %q: %w", c.SpanName, err)
+		}
+		cc.spanName = re
+	}
+	for _, name := range c.SpanKinds {
+		kind, ok := spanKinds[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown span kind %q (supported: %s)", name, strings.Join(sortedKeys(spanKinds), ", "))
+		}
+		cc.spanKinds = append(cc.spanKinds, kind)
+	}
+	if c.Where != "" {
+		where, err := parseWhere(c.Where, logger)
+		if err != nil {
+			return nil, err
+		}
+		cc.where = where
+	}
+	return cc, nil
+}
+
+// parseWhere parses an OTTL condition in the span context, with the standard
+// converters available.
+func parseWhere(where string, logger *zap.Logger) (*ottl.ConditionSequence[ottlspan.TransformContext], error) {
+	settings := component.TelemetrySettings{Logger: logger}
+	parser, err := ottlspan.NewParser(ottlfuncs.StandardConverters[ottlspan.TransformContext](), settings)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := parser.ParseCondition(where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid where %q: %w", where, err)
+	}
+	seq := ottlspan.NewConditionSequence([]*ottl.Condition[ottlspan.TransformContext]{cond}, settings,
+		ottlspan.WithConditionSequenceErrorMode(ottl.IgnoreError))
+	return &seq, nil
+}
+
+// match reports whether a record with attrs in context rc meets every
+// criterion of c. Span criteria and the where clause never match records
+// outside a span.
+func (c *condition) match(attrs pcommon.Map, rc recordContext) bool {
+	if c.scopeName != "" && rc.scope.Name() != c.scopeName {
+		return false
+	}
+	if c.scopeVersion != "" && rc.scope.Version() != c.scopeVersion {
+		return false
+	}
+	if c.serviceName != "" {
+		v, ok := rc.resource.Attributes().Get("service.name")
+		if !ok || v.AsString() != c.serviceName {
+			return false
+		}
+	}
+	if c.spanName != nil && (!rc.hasSpan || !c.spanName.MatchString(rc.span.Name())) {
+		return false
+	}
+	if len(c.spanKinds) > 0 {
+		if !rc.hasSpan {
+			return false
+		}
+		found := false
+		for _, kind := range c.spanKinds {
+			found = found || rc.span.Kind() == kind
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, key := range c.present {
+		if _, ok := attrs.Get(key); !ok {
+			return false
+		}
+	}
+	if c.where != nil {
+		if !rc.hasSpan {
+			return false
+		}
+		tc := ottlspan.NewTransformContext(rc.span, rc.scope, rc.resource, rc.scopeSpans, rc.resourceSpans)
+		if ok, _ := c.where.Eval(context.Background(), tc); !ok {
+			return false
+		}
+	}
+	return true
+}
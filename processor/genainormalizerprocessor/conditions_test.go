@@ -0,0 +1,192 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestProfileConditions(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileGoogle}
+	cfg.ProfileConditions = map[string]Condition{
+		ProfileGoogle: {ScopeName: "opentelemetry.instrumentation.vertexai"},
+	}
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	vertex := rs.ScopeSpans().AppendEmpty()
+	vertex.Scope().SetName("opentelemetry.instrumentation.vertexai")
+	llmSpan := vertex.Spans().AppendEmpty()
+	llmSpan.Attributes().PutStr("google.model", "gemini-1.5-pro")
+	storage := rs.ScopeSpans().AppendEmpty()
+	storage.Scope().SetName("google.cloud.storage")
+	otherSpan := storage.Spans().AppendEmpty()
+	otherSpan.Attributes().PutStr("google.model", "bucket-model")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	assertHasStr(t, llmSpan.Attributes(), "gen_ai.request.model", "gemini-1.5-pro")
+	assertHasStr(t, llmSpan.Attributes(), "gen_ai.system", "vertex_ai")
+	if _, ok := otherSpan.Attributes().Get("gen_ai.request.model"); ok {
+		t.Fatalf("expected the span outside the scope to be left alone, got %v", otherSpan.Attributes().AsRaw())
+	}
+}
+
+func TestRuleConditions(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Rules = []MappingRule{{
+		Source:      "model",
+		Destination: "gen_ai.request.model",
+		Condition: Condition{
+			ServiceName:       "chatbot",
+			SpanName:          "^chat ",
+			SpanKinds:         []string{"client"},
+			AttributesPresent: []string{"prompt"},
+		},
+	}}
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		service string
+		span    string
+		kind    ptrace.SpanKind
+		attrs   map[string]any
+		mapped  bool
+	}{
+		{name: "match", service: "chatbot", span: "chat gpt-4o", kind: ptrace.SpanKindClient, attrs: map[string]any{"model": "m", "prompt": "hi"}, mapped: true},
+		{name: "other service", service: "billing", span: "chat gpt-4o", kind: ptrace.SpanKindClient, attrs: map[string]any{"model": "m", "prompt": "hi"}},
+		{name: "other span name", service: "chatbot", span: "GET /model", kind: ptrace.SpanKindClient, attrs: map[string]any{"model": "m", "prompt": "hi"}},
+		{name: "other kind", service: "chatbot", span: "chat gpt-4o", kind: ptrace.SpanKindServer, attrs: map[string]any{"model": "m", "prompt": "hi"}},
+		{name: "missing attribute", service: "chatbot", span: "chat gpt-4o", kind: ptrace.SpanKindClient, attrs: map[string]any{"model": "m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := ptrace.NewTraces()
+			rs := td.ResourceSpans().AppendEmpty()
+			rs.Resource().Attributes().PutStr("service.name", tt.service)
+			sp := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			sp.SetName(tt.span)
+			sp.SetKind(tt.kind)
+			_ = sp.Attributes().FromRaw(tt.attrs)
+			ev := sp.Events().AppendEmpty()
+			ev.Attributes().PutStr("model", "m")
+			ev.Attributes().PutStr("prompt", "hi")
+
+			if err := p.ConsumeTraces(context.Background(), td); err != nil {
+				t.Fatalf("consume err: %v", err)
+			}
+			_, ok := sp.Attributes().Get("gen_ai.request.model")
+			if ok != tt.mapped {
+				t.Fatalf("expected mapped=%v, got %v", tt.mapped, sp.Attributes().AsRaw())
+			}
+			// Events are matched against their span.
+			_, ok = ev.Attributes().Get("gen_ai.request.model")
+			if want := tt.mapped || tt.name == "missing attribute"; ok != want {
+				t.Fatalf("expected event mapped=%v, got %v", want, ev.Attributes().AsRaw())
+			}
+		})
+	}
+}
+
+func TestConditionsOnLogs(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Rules = []MappingRule{
+		{Source: "model", Destination: "gen_ai.request.model", Condition: Condition{ServiceName: "chatbot"}},
+		{Source: "tokens", Destination: "gen_ai.usage.input_tokens", Condition: Condition{SpanKinds: []string{"client"}}},
+	}
+	p, err := newLogsProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "chatbot")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutStr("model", "m")
+	lr.Attributes().PutInt("tokens", 3)
+
+	if err := p.ConsumeLogs(context.Background(), ld); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	assertHasStr(t, lr.Attributes(), "gen_ai.request.model", "m")
+	// Span criteria never match log records.
+	if _, ok := lr.Attributes().Get("gen_ai.usage.input_tokens"); ok {
+		t.Fatalf("expected the span kind condition not to match, got %v", lr.Attributes().AsRaw())
+	}
+}
+
+func TestWhereConditions(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileGoogle}
+	cfg.ProfileConditions = map[string]Condition{
+		ProfileGoogle: {Where: `IsMatch(instrumentation_scope.name, "vertexai") and kind == SPAN_KIND_CLIENT`},
+	}
+	cfg.Rules = []MappingRule{{
+		Source:      "model",
+		Destination: "gen_ai.request.model",
+		Condition:   Condition{Where: `attributes["llm.request.type"] == "chat" and resource.attributes["service.name"] == "chatbot"`},
+	}}
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "chatbot")
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().SetName("opentelemetry.instrumentation.vertexai")
+	client := ss.Spans().AppendEmpty()
+	client.SetKind(ptrace.SpanKindClient)
+	_ = client.Attributes().FromRaw(map[string]any{"google.model": "gemini", "model": "gemini", "llm.request.type": "chat"})
+	server := ss.Spans().AppendEmpty()
+	server.SetKind(ptrace.SpanKindServer)
+	_ = server.Attributes().FromRaw(map[string]any{"google.model": "gemini", "model": "gemini", "llm.request.type": "completion"})
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	assertHasStr(t, client.Attributes(), "gen_ai.request.model", "gemini")
+	assertHasStr(t, client.Attributes(), "gen_ai.system", "vertex_ai")
+	if _, ok := server.Attributes().Get("gen_ai.request.model"); ok {
+		t.Fatalf("expected the server span to be left alone, got %v", server.Attributes().AsRaw())
+	}
+
+	// A where clause never matches records outside a span.
+	lp, err := newLogsProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	_ = lr.Attributes().FromRaw(map[string]any{"model": "gemini", "llm.request.type": "chat"})
+	if err := lp.ConsumeLogs(context.Background(), ld); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	if _, ok := lr.Attributes().Get("gen_ai.request.model"); ok {
+		t.Fatalf("expected the log record to be left alone, got %v", lr.Attributes().AsRaw())
+	}
+}
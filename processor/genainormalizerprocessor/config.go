@@ -1,5 +1,15 @@
 package genainormalizerprocessor
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
 // Config defines configuration for the GenAI semantic normalizer.
 //
 // The processor maps framework/vendor-specific GenAI attribute names to the
@@ -9,34 +19,420 @@ package genainormalizerprocessor
 //   mappings:
 //     llm.model_name: gen_ai.request.model
 //     llm.provider:   gen_ai.provider.name
+//   rules:
+//     - source: llm.usage.prompt_tokens
+//       destination: gen_ai.usage.input_tokens
+//       type: int
+//       on_failure: drop
 //   overwrite: false
 //   drop_original: false
 //
 // Notes:
-// - Mapping is applied to span attributes AND span event attributes.
+// - Mapping is applied to resource, span, span event and span link attributes
+//   by default; targets (globally or per rule) selects resource, scope, span,
+//   event, link, log and datapoint attribute maps.
+// - For logs, mapping is applied to resource and log record attributes, and to
+//   the event.name of GenAI events.
+// - For metrics, metric_mappings renames metric names and mappings is applied to
+//   the attributes of every data point.
 // - If overwrite is false and destination already exists, the destination is left untouched.
 // - If drop_original is true, the source key is removed when it differs from the destination.
+// - Chained mappings (a -> b and b -> c, across every table) are resolved to
+//   a single hop (a -> c); cycles are rejected.
+// - When several source keys map to the same destination, the sources are tried
+//   in priorities order, then in the order of rules, mappings, custom_mappings,
+//   the mapping files (last file first) and profile mappings, map-form tables
+//   sorted by key, and conflict_policy decides which present value wins.
+// - gen_ai.system is inferred from the vendor key prefixes of the enabled
+//   profiles (openai.*, anthropic.*, ...) on spans, log records and data points
+//   when it is not already set.
 
 type Config struct {
 	// Mappings is a map of source_attribute_key -> destination_attribute_key.
 	Mappings map[string]string `mapstructure:"mappings"`
 
+	// Rules are long-form mappings that may declare a value type. They take
+	// precedence over Mappings for the same source key.
+	Rules []MappingRule `mapstructure:"rules"`
+
+	// MetricMappings is a map of source_metric_name -> destination_metric_name.
+	MetricMappings map[string]string `mapstructure:"metric_mappings"`
+
+	// CustomMappings is the deprecated spelling of Mappings. Entries are merged
+	// into Mappings, which wins when both set the same source key.
+	CustomMappings map[string]string `mapstructure:"custom_mappings"`
+
+	// CustomMetricMappings is the deprecated spelling of MetricMappings.
+	CustomMetricMappings map[string]string `mapstructure:"custom_metric_mappings"`
+
+	// Priorities lists, per destination key, the source keys in the order they
+	// are preferred when more than one is present. Listed sources are mapped to
+	// that destination even if they do not appear in Mappings.
+	Priorities map[string][]string `mapstructure:"priorities"`
+
+	// ConflictPolicy selects the winning source when several are present:
+	// first_wins (default), last_wins, prefer_non_empty or error_attribute.
+	ConflictPolicy string `mapstructure:"conflict_policy"`
+
+	// SemconvTypes coerces values written to known gen_ai.* keys to the type
+	// defined by the semantic conventions (for example a scalar stop reason
+	// becomes a one-element gen_ai.response.finish_reasons array) unless the
+	// mapping declares its own type.
+	SemconvTypes bool `mapstructure:"semconv_types"`
+
+	// TargetSemconvVersion, when set, rewrites deprecated gen_ai.* keys and
+	// enum values (gen_ai.system, gen_ai.prompt, gen_ai.completion, old usage
+	// keys, ...) to the spelling of that semantic conventions version, e.g.
+	// "1.37.0", and stamps the matching schema URL on resources and scopes.
+	TargetSemconvVersion string `mapstructure:"target_semconv_version"`
+
+	// DualEmit writes both spellings of every migrated key during a semconv
+	// transition.
+	DualEmit DualEmitConfig `mapstructure:"dual_emit"`
+
+	// Targets lists the attribute maps mappings apply to unless a rule sets
+	// its own: resource, scope, span, event, link, log and datapoint. Defaults to
+	// every target except scope.
+	Targets []string `mapstructure:"targets"`
+
+	// Hoist copies keys between the resource and its GenAI spans and log
+	// records after normalization.
+	Hoist HoistConfig `mapstructure:"hoist"`
+
+	// Profiles enables built-in profiles by name. Each profile bundles the
+	// mappings, gen_ai.system inference and transforms for one
+	// instrumentation: llm, openai, anthropic, cohere, azure, google, bedrock
+	// (aws.bedrock.* attributes and JSON request/response bodies), langchain
+	// (LangChain and LlamaIndex callback runs), vllm, ollama, tgi, llamacpp
+	// (self-hosted inference servers), openinference (reassembles flattened llm.input_messages.N.* and
+	// llm.output_messages.N.* attributes into GenAI message events) and
+	// traceloop (the same for the indexed gen_ai.prompt.N.* and
+	// gen_ai.completion.N.* attributes of OpenLLMetry).
+	Profiles []string `mapstructure:"profiles"`
+
+	// ProfileConditions restricts profiles, by name, to the records that meet
+	// a condition; a profile without one applies to every record.
+	ProfileConditions map[string]Condition `mapstructure:"profile_conditions"`
+
+	// MessageOutput selects how profiles record the messages they reassemble:
+	// events (default) as GenAI events, attributes as the JSON encoded
+	// gen_ai.input.messages and gen_ai.output.messages, or both.
+	MessageOutput string `mapstructure:"message_output"`
+
+	// MappingFiles lists YAML or JSON files holding mappings, rules,
+	// metric_mappings, priorities, profiles and profile_conditions with the same
+	// schema as the inline configuration. Files are merged in the listed order,
+	// later files winning over earlier ones; inline settings win over every file.
+	MappingFiles []string `mapstructure:"mapping_files"`
+
+	// ReloadInterval is how often the mapping files are checked for changes.
+	// Changed files are reloaded without restarting the collector. Zero
+	// disables reloading.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+
+	// DeriveUsage fills a missing gen_ai.usage.total_tokens from the input and
+	// output counts of spans and log records, or a missing output count from
+	// the total and input counts, and sets
+	// genai_normalizer.usage_inconsistent when the counts contradict each
+	// other.
+	DeriveUsage bool `mapstructure:"derive_usage"`
+
+	// ModelFamily writes the canonical name of the model of spans, log
+	// records and data points to a separate attribute.
+	ModelFamily ModelFamilyConfig `mapstructure:"model_family"`
+
+	// Cost estimates the cost of spans and log records from a price table.
+	Cost CostConfig `mapstructure:"cost"`
+
 	// Overwrite controls whether existing destination keys may be overwritten.
 	Overwrite bool `mapstructure:"overwrite"`
 
 	// DropOriginal controls whether to remove the original (source) attribute when mapped.
 	DropOriginal bool `mapstructure:"drop_original"`
 
-	// EnableDefaults toggles the built-in default mapping set.
-	// If false, only user-provided mappings are used.
+	// Strict rejects mappings, rules and metric_mappings whose destination is
+	// outside the gen_ai.* namespace.
+	Strict bool `mapstructure:"strict"`
+
+	// EnableDefaults enables the llm, openai, anthropic, cohere, azure and
+	// google profiles when Profiles is empty. If false and Profiles is empty,
+	// only user-provided mappings are used.
 	EnableDefaults bool `mapstructure:"enable_defaults"`
 }
 
+var _ component.ConfigValidator = (*Config)(nil)
+
+// Validate checks the configuration and returns every problem found, each
+// naming the offending keys.
+func (cfg *Config) Validate() error {
+	var errs []error
+	errs = append(errs, validateMap("mappings", cfg.Mappings, cfg.Strict)...)
+	errs = append(errs, validateMap("custom_mappings", cfg.CustomMappings, cfg.Strict)...)
+	errs = append(errs, validateMap("metric_mappings", cfg.MetricMappings, cfg.Strict)...)
+	errs = append(errs, validateMap("custom_metric_mappings", cfg.CustomMetricMappings, cfg.Strict)...)
+	errs = append(errs, validateRules(cfg.Rules, cfg.Strict)...)
+	errs = append(errs, validatePriorities(cfg.Priorities)...)
+
+	g := mappingGraph{}
+	for _, r := range cfg.Rules {
+		g.add(r.Source, r.Destination)
+	}
+	for _, m := range []map[string]string{cfg.Mappings, cfg.CustomMappings} {
+		for src, dst := range m {
+			g.add(src, dst)
+		}
+	}
+	// Profile mappings only apply to the sources the settings above leave
+	// unmapped. Mapping files are checked when they are loaded.
+	profiles, err := enabledProfiles(cfg, nil)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, e := range profileEntries(profiles) {
+		if _, claimed := g[e.src]; !claimed {
+			g.add(e.src, e.dst)
+		}
+	}
+	for _, cycle := range g.cycles() {
+		errs = append(errs, fmt.Errorf("mapping cycle %s", formatPath(append(cycle, cycle[0]))))
+	}
+
+	if err := validateConflictPolicy(cfg.ConflictPolicy); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateMessageOutput(cfg.MessageOutput); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateTargets(cfg.Targets); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, validateProfileConditions(cfg.ProfileConditions)...)
+	if _, err := newMigrator(cfg.TargetSemconvVersion, cfg.DualEmit); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := compileModelRules(cfg.ModelFamily); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.ReloadInterval < 0 {
+		errs = append(errs, fmt.Errorf("reload_interval must not be negative, got %s", cfg.ReloadInterval))
+	}
+	return errors.Join(errs...)
+}
+
+// validateMap checks the source -> destination map of the named setting.
+func validateMap(name string, m map[string]string, strict bool) []error {
+	var errs []error
+	for _, src := range sortedKeys(m) {
+		if err := validateMapping(src, m[src], strict); err != nil {
+			errs = append(errs, fmt.Errorf("%s[%q]: %w", name, src, err))
+		}
+	}
+	return errs
+}
+
+// validateRules checks the rules, including their type, on_failure and
+// targets.
+func validateRules(rules []MappingRule, strict bool) []error {
+	var errs []error
+	for i, r := range rules {
+		if err := validateMapping(r.Source, r.Destination, strict); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d] (%s): %w", i, r.Source, err))
+		}
+		if _, err := newConverter(r.Type, r.OnFailure); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d] (%s): %w", i, r.Source, err))
+		}
+		if err := validateTargets(r.Targets); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d] (%s): %w", i, r.Source, err))
+		}
+		if _, err := compileCondition(r.Condition, zap.NewNop()); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d] (%s): condition: %w", i, r.Source, err))
+		}
+	}
+	return errs
+}
+
+// validateProfileConditions checks that the conditions compile and name
+// built-in profiles.
+func validateProfileConditions(conditions map[string]Condition) []error {
+	var errs []error
+	for _, name := range sortedKeys(conditions) {
+		if _, ok := builtinProfiles[name]; !ok {
+			errs = append(errs, fmt.Errorf("profile_conditions: unknown profile %q", name))
+		}
+		if _, err := compileCondition(conditions[name], zap.NewNop()); err != nil {
+			errs = append(errs, fmt.Errorf("profile_conditions[%q]: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// validatePriorities checks that no destination or source key is empty.
+func validatePriorities(priorities map[string][]string) []error {
+	var errs []error
+	for _, dst := range sortedKeys(priorities) {
+		if dst == "" {
+			errs = append(errs, errors.New("priorities: empty destination key"))
+		}
+		for i, src := range priorities[dst] {
+			if src == "" {
+				errs = append(errs, fmt.Errorf("priorities[%q][%d]: empty source key", dst, i))
+			}
+		}
+	}
+	return errs
+}
+
+func validateMapping(src, dst string, strict bool) error {
+	switch {
+	case src == "":
+		return fmt.Errorf("empty source key for destination %q", dst)
+	case dst == "":
+		return errors.New("empty destination key")
+	case src == dst:
+		return errors.New("source key maps to itself")
+	case strict && !strings.HasPrefix(dst, "gen_ai."):
+		return fmt.Errorf("destination %q is outside gen_ai.* (strict)", dst)
+	}
+	return nil
+}
+
+// HoistConfig lists keys moved between a resource and the GenAI spans or log
+// records under it. A record is a GenAI record when it has a gen_ai.* key.
+type HoistConfig struct {
+	// Down copies these resource attributes onto every GenAI record that
+	// lacks them.
+	Down []string `mapstructure:"down"`
+
+	// Up copies these attributes onto the resource when every GenAI record
+	// carrying them agrees on the value and the resource lacks them.
+	Up []string `mapstructure:"up"`
+}
+
+// ModelFamilyConfig configures model name canonicalization. The canonical
+// name of gen_ai.request.model (or gen_ai.response.model when the request
+// model is missing) is written to Attribute, keeping the raw value, so that
+// gpt-4o-2024-08-06, azure/gpt-4o and openai/gpt-4o all become gpt-4o.
+type ModelFamilyConfig struct {
+	// Enabled turns canonicalization on.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Attribute receives the canonical name. Defaults to
+	// gen_ai.request.model.family.
+	Attribute string `mapstructure:"attribute"`
+
+	// Rules rewrite the model name in order, before the built-in rules that
+	// strip provider, region and vendor prefixes and version and date
+	// suffixes.
+	Rules []ModelRule `mapstructure:"rules"`
+
+	// DisableDefaultRules applies Rules only.
+	DisableDefaultRules bool `mapstructure:"disable_default_rules"`
+}
+
+// ModelRule replaces the matches of an RE2 pattern in the model name.
+type ModelRule struct {
+	Pattern string `mapstructure:"pattern"`
+
+	// Replacement may refer to the pattern groups as $1 or ${name}. Empty
+	// removes the match.
+	Replacement string `mapstructure:"replacement"`
+}
+
+// CostConfig configures cost estimation. The cost of a span or log record
+// is written to gen_ai.usage.cost, and the currency of the price table to
+// gen_ai.usage.cost.currency.
+type CostConfig struct {
+	// PriceFile is a YAML or JSON price table listing, per model name prefix,
+	// the input, output, cached input and batch rates and the date they take
+	// effect. It is reloaded with the mapping files. Empty disables cost
+	// estimation.
+	PriceFile string `mapstructure:"price_file"`
+
+	// BatchAttribute names the attribute marking batch requests, which are
+	// priced at the batch rates: its value is true or "batch". Empty prices
+	// every request at the regular rates.
+	BatchAttribute string `mapstructure:"batch_attribute"`
+}
+
+// DualEmitConfig configures writing both the legacy and the current spelling
+// of migrated keys, so consumers can move to the new keys gradually.
+type DualEmitConfig struct {
+	// Enabled turns dual emission on. Without target_semconv_version the
+	// latest supported version is targeted.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Sunset lists legacy keys (e.g. gen_ai.system) that are no longer
+	// emitted next to their replacement.
+	Sunset []string `mapstructure:"sunset"`
+}
+
+// MappingRule maps one source key (exact, glob or regex, as in Mappings) to a
+// destination key and optionally coerces the value.
+type MappingRule struct {
+	Source      string `mapstructure:"source" yaml:"source"`
+	Destination string `mapstructure:"destination" yaml:"destination"`
+
+	// Type is the destination value type: string, int, double, bool, or one
+	// of those suffixed with [] for a slice. Empty copies the value as is.
+	Type string `mapstructure:"type" yaml:"type"`
+
+	// Targets restricts the rule to some attribute maps; see Config.Targets.
+	Targets []string `mapstructure:"targets" yaml:"targets"`
+
+	// OnFailure is applied when the value cannot be coerced to Type: keep
+	// (default) writes it unchanged, drop skips the destination and
+	// error_attribute skips it and records the key in
+	// genai_normalizer.coercion_errors.
+	OnFailure string `mapstructure:"on_failure" yaml:"on_failure"`
+
+	// Condition, when set, restricts the rule to the records that meet it. A
+	// rule replaces every other mapping of its source key, so a conditional
+	// rule also restricts mappings of that key made by profiles.
+	Condition Condition `mapstructure:"condition" yaml:"condition"`
+}
+
+// Condition restricts a rule or profile to some records. Every criterion set
+// must hold.
+type Condition struct {
+	// ScopeName and ScopeVersion must equal those of the instrumentation
+	// scope of the record.
+	ScopeName    string `mapstructure:"scope_name" yaml:"scope_name"`
+	ScopeVersion string `mapstructure:"scope_version" yaml:"scope_version"`
+
+	// ServiceName must equal the service.name resource attribute.
+	ServiceName string `mapstructure:"service_name" yaml:"service_name"`
+
+	// SpanName is an RE2 regular expression the span name must match. For
+	// span events and links the enclosing span is used; other records never
+	// match.
+	SpanName string `mapstructure:"span_name" yaml:"span_name"`
+
+	// SpanKinds lists the accepted span kinds: internal, server, client,
+	// producer or consumer. Like SpanName, it only matches spans, span events
+	// and links.
+	SpanKinds []string `mapstructure:"span_kinds" yaml:"span_kinds"`
+
+	// AttributesPresent lists keys that must all be present on the
+	// attributes being normalized, before any mapping.
+	AttributesPresent []string `mapstructure:"attributes_present" yaml:"attributes_present"`
+
+	// Where is an OTTL condition evaluated in the span context, e.g.
+	// `attributes["llm.request.type"] == "chat" and kind == SPAN_KIND_CLIENT`.
+	// For span events and links the enclosing span is used; other records
+	// never match. It is evaluated before the span is normalized, while
+	// events and links see their span after normalization.
+	Where string `mapstructure:"where" yaml:"where"`
+}
+
 func createDefaultConfig() *Config {
 	return &Config{
 		Mappings:       map[string]string{},
+		MetricMappings: map[string]string{},
+		ConflictPolicy: ConflictPolicyFirstWins,
+		SemconvTypes:   true,
 		Overwrite:      false,
 		DropOriginal:   false,
+		ReloadInterval: 30 * time.Second,
 		EnableDefaults: true,
 	}
 }
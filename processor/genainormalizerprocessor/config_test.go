@@ -0,0 +1,127 @@
+package genainormalizerprocessor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		errs   []string
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "empty keys",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"": "gen_ai.request.model", "llm.model": ""}
+				cfg.Rules = []MappingRule{{Source: "", Destination: "gen_ai.system"}}
+				cfg.Priorities = map[string][]string{"gen_ai.request.model": {"llm.model", ""}}
+			},
+			errs: []string{
+				`mappings[""]: empty source key for destination "gen_ai.request.model"`,
+				`mappings["llm.model"]: empty destination key`,
+				`rules[0] (): empty source key for destination "gen_ai.system"`,
+				`priorities["gen_ai.request.model"][1]: empty source key`,
+			},
+		},
+		{
+			name: "self mapping",
+			modify: func(cfg *Config) {
+				cfg.CustomMappings = map[string]string{"gen_ai.system": "gen_ai.system"}
+			},
+			errs: []string{`custom_mappings["gen_ai.system"]: source key maps to itself`},
+		},
+		{
+			name: "strict",
+			modify: func(cfg *Config) {
+				cfg.Strict = true
+				cfg.Mappings = map[string]string{"llm.model": "gen_ai.request.model", "llm.host": "server.address"}
+				cfg.MetricMappings = map[string]string{"llm.tokens": "llm.token.usage"}
+			},
+			errs: []string{
+				`mappings["llm.host"]: destination "server.address" is outside gen_ai.* (strict)`,
+				`metric_mappings["llm.tokens"]: destination "llm.token.usage" is outside gen_ai.* (strict)`,
+			},
+		},
+		{
+			name: "cycle",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"b": "a"}
+				cfg.Rules = []MappingRule{{Source: "a", Destination: "b"}}
+			},
+			errs: []string{"mapping cycle a -> b -> a"},
+		},
+		{
+			name: "cycle through default profiles",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"gen_ai.request.model": "llm.model"}
+			},
+			errs: []string{"mapping cycle gen_ai.request.model -> llm.model -> gen_ai.request.model"},
+		},
+		{
+			name: "profile source overridden",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"llm.model": "vendor.model", "vendor.model": "gen_ai.request.model"}
+			},
+		},
+		{
+			name: "invalid settings",
+			modify: func(cfg *Config) {
+				cfg.ConflictPolicy = "random"
+				cfg.Profiles = []string{"acme"}
+				cfg.Rules = []MappingRule{{Source: "llm.tokens", Destination: "gen_ai.usage.input_tokens", Type: "long"}}
+			},
+			errs: []string{
+				`unknown conflict_policy "random"`,
+				`unknown profile "acme"`,
+				`rules[0] (llm.tokens): unknown type "long"`,
+			},
+		},
+		{
+			name: "invalid conditions",
+			modify: func(cfg *Config) {
+				cfg.Rules = []MappingRule{{Source: "model", Destination: "gen_ai.request.model", Condition: Condition{SpanKinds: []string{"rpc"}}}}
+				cfg.ProfileConditions = map[string]Condition{"acme": {}, ProfileGoogle: {SpanName: "("}, ProfileOpenAI: {Where: `name ==`}}
+			},
+			errs: []string{
+				`rules[0] (model): condition: unknown span kind "rpc"`,
+				`profile_conditions: unknown profile "acme"`,
+				`profile_conditions["google"]: invalid span_name "("`,
+				`profile_conditions["openai"]: invalid where "name =="`,
+			},
+		},
+		{
+			name: "invalid model rule",
+			modify: func(cfg *Config) {
+				cfg.ModelFamily.Rules = []ModelRule{{Pattern: "gpt-(4"}}
+			},
+			errs: []string{`model_family.rules[0]: invalid pattern "gpt-(4"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if len(tt.errs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %q", tt.errs)
+			}
+			for _, want := range tt.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected %q in %q", want, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package genainormalizerprocessor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"gopkg.in/yaml.v3"
+)
+
+// Cost keys. Neither is part of the semantic conventions.
+const (
+	costAttr         = "gen_ai.usage.cost"
+	costCurrencyAttr = "gen_ai.usage.cost.currency"
+)
+
+// defaultPriceUnit is the number of tokens the rates of a price table are
+// given for, unless the table sets its own.
+const defaultPriceUnit = 1_000_000
+
+// priceTable is the content of a price file. JSON files are read as YAML.
+//
+//	currency: USD
+//	unit: 1000000
+//	prices:
+//	  - model: gpt-4o
+//	    effective_from: 2024-08-06
+//	    input: 2.5
+//	    output: 10
+//	    cached_input: 1.25
+//	    batch_input: 1.25
+//	    batch_output: 5
+type priceTable struct {
+	Currency string  `yaml:"currency"`
+	Unit     float64 `yaml:"unit"`
+	Prices   []price `yaml:"prices"`
+}
+
+// price holds the rates of Model from EffectiveFrom on. A Model ending in *
+// is a prefix and prices every model whose name starts with it. Optional
+// rates default to the input or output rate.
+type price struct {
+	Model         string `yaml:"model"`
+	EffectiveFrom string `yaml:"effective_from"`
+
+	Input              float64  `yaml:"input"`
+	Output             float64  `yaml:"output"`
+	CachedInput        *float64 `yaml:"cached_input"`
+	CacheCreationInput *float64 `yaml:"cache_creation_input"`
+	BatchInput         *float64 `yaml:"batch_input"`
+	BatchOutput        *float64 `yaml:"batch_output"`
+
+	// InputIncludesCache tells whether the input token count of the model
+	// includes the cached tokens (OpenAI) or not (Anthropic). Defaults to
+	// true.
+	InputIncludesCache *bool `yaml:"input_includes_cache"`
+
+	name   string
+	prefix bool
+	from   time.Time
+}
+
+// parsePriceTable decodes the price file at path. Prices are sorted by model
+// name, longest first, then by effective date, latest first.
+func parsePriceTable(path string, content []byte) (*priceTable, error) {
+	var t priceTable
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("price file %s: %w", path, err)
+	}
+	if t.Unit == 0 {
+		t.Unit = defaultPriceUnit
+	}
+	if t.Unit < 0 {
+		return nil, fmt.Errorf("price file %s: unit must be positive, got %v", path, t.Unit)
+	}
+	for i := range t.Prices {
+		p := &t.Prices[i]
+		if p.Model == "" {
+			return nil, fmt.Errorf("price file %s: prices[%d]: empty model", path, i)
+		}
+		p.name, p.prefix = strings.CutSuffix(p.Model, "*")
+		if p.EffectiveFrom != "" {
+			from, err := parseEffectiveDate(p.EffectiveFrom)
+			if err != nil {
+				return nil, fmt.Errorf("price file %s: prices[%d] (%s): %w", path, i, p.Model, err)
+			}
+			p.from = from
+		}
+	}
+	sort.SliceStable(t.Prices, func(i, j int) bool {
+		a, b := t.Prices[i], t.Prices[j]
+		if len(a.name) != len(b.name) {
+			return len(a.name) > len(b.name)
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.from.After(b.from)
+	})
+	return &t, nil
+}
+
+// parseEffectiveDate accepts a date (2024-08-06, in UTC) or an RFC 3339
+// timestamp.
+func parseEffectiveDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid effective_from %q: use YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// lookup returns the latest price in effect at t of model, else of its
+// canonical family, else of the longest prefix entry matching either.
+func (t *priceTable) lookup(model, family string, at time.Time) (price, bool) {
+	for _, name := range []string{model, family} {
+		for _, p := range t.Prices {
+			if !p.prefix && p.name == name && !p.from.After(at) {
+				return p, true
+			}
+		}
+	}
+	for _, p := range t.Prices {
+		if p.prefix && (strings.HasPrefix(model, p.name) || strings.HasPrefix(family, p.name)) && !p.from.After(at) {
+			return p, true
+		}
+	}
+	return price{}, false
+}
+
+// recordTime returns ts, or the current time when ts is unset.
+func recordTime(ts pcommon.Timestamp) time.Time {
+	if ts == 0 {
+		return time.Now()
+	}
+	return ts.AsTime()
+}
+
+func rate(r *float64, fallback float64) float64 {
+	if r == nil {
+		return fallback
+	}
+	return *r
+}
+
+// cost returns the cost of the token counts at price p.
+func (t *priceTable) cost(p price, u tokenUsage, batch bool) float64 {
+	inputRate, outputRate := p.Input, p.Output
+	if batch {
+		inputRate, outputRate = rate(p.BatchInput, p.Input), rate(p.BatchOutput, p.Output)
+	}
+	uncached := u.input
+	if p.InputIncludesCache == nil || *p.InputIncludesCache {
+		uncached = max(u.input-u.cacheRead-u.cacheCreation, 0)
+	}
+	total := float64(uncached)*inputRate +
+		float64(u.cacheRead)*rate(p.CachedInput, inputRate) +
+		float64(u.cacheCreation)*rate(p.CacheCreationInput, inputRate) +
+		float64(u.output)*outputRate
+	return total / t.Unit
+}
+
+// tokenUsage is the token counts of a record.
+type tokenUsage struct {
+	input, output, cacheRead, cacheCreation int64
+}
+
+// estimateCost writes gen_ai.usage.cost and its currency for the record
+// with attrs, observed at the given time, when its model has a price. The
+// response model is preferred over the request model.
+func (n *normalizer) estimateCost(attrs pcommon.Map, at time.Time) {
+	if n.prices == nil {
+		return
+	}
+	var u tokenUsage
+	var hasInput, hasOutput bool
+	u.input, hasInput = usageCount(attrs, inputTokensAttr)
+	u.output, hasOutput = usageCount(attrs, outputTokensAttr)
+	if !hasInput && !hasOutput {
+		return
+	}
+	u.cacheRead, _ = usageCount(attrs, cacheReadTokensAttr)
+	u.cacheCreation, _ = usageCount(attrs, cacheCreationTokensAttr)
+
+	p, ok := n.modelPrice(attrs, at)
+	if !ok {
+		return
+	}
+	n.put(attrs, costAttr, pcommon.NewValueDouble(n.prices.cost(p, u, n.isBatch(attrs))))
+	if n.prices.Currency != "" {
+		n.put(attrs, costCurrencyAttr, pcommon.NewValueStr(n.prices.Currency))
+	}
+}
+
+// modelPrice looks up the price of the response model, then of the request
+// model.
+func (n *normalizer) modelPrice(attrs pcommon.Map, at time.Time) (price, bool) {
+	for _, key := range []string{"gen_ai.response.model", "gen_ai.request.model"} {
+		if v, ok := attrs.Get(key); ok && v.Str() != "" {
+			if p, ok := n.prices.lookup(v.Str(), canonicalModel(n.modelRules, v.Str()), at); ok {
+				return p, true
+			}
+		}
+	}
+	return price{}, false
+}
+
+// isBatch reports whether the record is a batch request: its batch
+// attribute is true or "batch".
+func (n *normalizer) isBatch(attrs pcommon.Map) bool {
+	if n.batchAttr == "" {
+		return false
+	}
+	v, ok := attrs.Get(n.batchAttr)
+	if !ok {
+		return false
+	}
+	if b, ok := asBool(v); ok {
+		return b
+	}
+	return v.Type() == pcommon.ValueTypeStr && v.Str() == "batch"
+}
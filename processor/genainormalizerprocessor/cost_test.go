@@ -0,0 +1,160 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+const testPrices = `
+currency: USD
+prices:
+  - model: gpt-4o
+    input: 5
+    output: 15
+  - model: gpt-4o
+    effective_from: 2024-08-06
+    input: 2.5
+    output: 10
+    cached_input: 1.25
+    batch_input: 1.25
+    batch_output: 5
+  - model: gpt-4o-mini
+    input: 0.15
+    output: 0.6
+  - model: gpt-4
+    input: 30
+    output: 60
+  - model: ft:gpt-4o*
+    input: 3.75
+    output: 15
+  - model: claude-3-5-sonnet
+    input: 3
+    output: 15
+    cached_input: 0.3
+    cache_creation_input: 3.75
+    input_includes_cache: false
+`
+
+func TestPriceLookup(t *testing.T) {
+	table, err := parsePriceTable("prices.yaml", []byte(testPrices))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	rules, err := compileModelRules(ModelFamilyConfig{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	tests := []struct {
+		model string
+		at    string
+		input float64
+		ok    bool
+	}{
+		{model: "gpt-4o-2024-08-06", at: "2024-09-01", input: 2.5, ok: true},
+		{model: "gpt-4o-2024-05-13", at: "2024-06-01", input: 5, ok: true},
+		{model: "gpt-4o-mini-2024-07-18", at: "2024-09-01", input: 0.15, ok: true},
+		{model: "azure/gpt-4o", at: "2024-09-01", input: 2.5, ok: true},
+		{model: "gpt-4", at: "2024-09-01", input: 30, ok: true},
+		{model: "gpt-4-0613", at: "2024-09-01", input: 30, ok: true},
+		{model: "ft:gpt-4o-2024-08-06:acme::abc123", at: "2024-09-01", input: 3.75, ok: true},
+		{model: "gpt-4-turbo", at: "2024-09-01"},
+		{model: "gpt-4o-audio-preview", at: "2024-09-01"},
+		{model: "gpt-3.5-turbo", at: "2024-09-01"},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.DateOnly, tt.at)
+		p, ok := table.lookup(tt.model, canonicalModel(rules, tt.model), at)
+		if ok != tt.ok || p.Input != tt.input {
+			t.Errorf("lookup(%s, %s) = %v, %v; want input %v, %v", tt.model, tt.at, p.Input, ok, tt.input, tt.ok)
+		}
+	}
+}
+
+func TestInvalidPriceFile(t *testing.T) {
+	_, err := parsePriceTable("prices.yaml", []byte("prices:\n  - model: gpt-4o\n    effective_from: yesterday\n"))
+	if err == nil || !strings.Contains(err.Error(), `price file prices.yaml: prices[0] (gpt-4o): invalid effective_from "yesterday"`) {
+		t.Fatalf("expected effective_from error, got %v", err)
+	}
+}
+
+func TestCostEstimation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	writeFile(t, path, testPrices)
+
+	cfg := createDefaultConfig()
+	cfg.Cost = CostConfig{PriceFile: path, BatchAttribute: "openai.batch"}
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		start string
+		attrs map[string]any
+		cost  float64
+	}{
+		{
+			name:  "current price with cached input",
+			start: "2024-09-01",
+			attrs: map[string]any{"gen_ai.response.model": "gpt-4o-2024-08-06", "gen_ai.usage.input_tokens": 1000, "gen_ai.usage.output_tokens": 100, "gen_ai.usage.cache_read.input_tokens": 400},
+			cost:  (600*2.5 + 400*1.25 + 100*10) / 1e6,
+		},
+		{
+			name:  "earlier price",
+			start: "2024-06-01",
+			attrs: map[string]any{"gen_ai.request.model": "gpt-4o", "gen_ai.usage.input_tokens": 1000, "gen_ai.usage.output_tokens": 100},
+			cost:  (1000*5 + 100*15) / 1e6,
+		},
+		{
+			name:  "batch",
+			start: "2024-09-01",
+			attrs: map[string]any{"gen_ai.request.model": "gpt-4o", "openai.batch": true, "gen_ai.usage.input_tokens": 1000, "gen_ai.usage.output_tokens": 100},
+			cost:  (1000*1.25 + 100*5) / 1e6,
+		},
+		{
+			name:  "cache outside input",
+			start: "2024-09-01",
+			attrs: map[string]any{"anthropic.model": "claude-3-5-sonnet-20240620", "anthropic.input_tokens": 10, "anthropic.output_tokens": 100, "anthropic.cache_read_input_tokens": 2000, "anthropic.cache_creation_input_tokens": 500},
+			cost:  (10*3 + 2000*0.3 + 500*3.75 + 100*15) / 1e6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := ptrace.NewTraces()
+			sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			start, _ := time.Parse(time.DateOnly, tt.start)
+			sp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+			_ = sp.Attributes().FromRaw(tt.attrs)
+			if err := p.ConsumeTraces(context.Background(), td); err != nil {
+				t.Fatalf("consume err: %v", err)
+			}
+			v, ok := sp.Attributes().Get(costAttr)
+			if !ok || math.Abs(v.Double()-tt.cost) > 1e-12 {
+				t.Fatalf("expected cost %v, got %v", tt.cost, sp.Attributes().AsRaw())
+			}
+			assertHasStr(t, sp.Attributes(), costCurrencyAttr, "USD")
+		})
+	}
+
+	// Models without a price get no cost.
+	td, sp := spanWith(map[string]any{"gen_ai.request.model": "llama3", "gen_ai.usage.input_tokens": 10})
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	if _, ok := sp.Attributes().Get(costAttr); ok {
+		t.Fatalf("expected no cost, got %v", sp.Attributes().AsRaw())
+	}
+}
@@ -1,18 +1,22 @@
 package genainormalizerprocessor
 
-// defaultMappings provides a minimal, opinionated starting point.
-// It is intentionally small: users should extend it as their stack dictates.
+// The mapping tables below back the built-in vendor profiles (see
+// profiles.go). They provide a minimal, opinionated starting point and are
+// intentionally small: users should extend them as their stack dictates.
 //
 // The destination keys follow the OpenTelemetry GenAI semantic conventions (gen_ai.*).
 // See: https://opentelemetry.io/docs/specs/semconv/registry/attributes/gen-ai/
-var defaultMappings = map[string]string{
-	// Common "LLM" / OpenInference-style keys.
+
+// llmMappings covers the generic "llm.*" keys emitted by many libraries.
+var llmMappings = map[string]string{
 	"llm.provider":   "gen_ai.provider.name",
 	"llm.model_name": "gen_ai.request.model",
 	"llm.model":      "gen_ai.request.model",
+	"llm.prompt":     "gen_ai.prompt",
+	"llm.completion": "gen_ai.completion",
 
 	// Operation names
-	"llm.operation":   "gen_ai.operation.name",
+	"llm.operation":      "gen_ai.operation.name",
 	"llm.operation_name": "gen_ai.operation.name",
 
 	// Token usage (frequently emitted by libraries with slightly different naming)
@@ -24,6 +28,10 @@ var defaultMappings = map[string]string{
 	"llm.token_count.completion": "gen_ai.usage.output_tokens",
 	"llm.token_count.total":      "gen_ai.usage.total_tokens",
 
+	"llm.usage.cache_read_input_tokens":     "gen_ai.usage.cache_read.input_tokens",
+	"llm.usage.cache_creation_input_tokens": "gen_ai.usage.cache_creation.input_tokens",
+	"llm.usage.reasoning_tokens":            "gen_ai.usage.reasoning.output_tokens",
+
 	// Request parameters (common across providers)
 	"llm.request.temperature": "gen_ai.request.temperature",
 	"llm.request.top_p":       "gen_ai.request.top_p",
@@ -32,3 +40,86 @@ var defaultMappings = map[string]string{
 	// Response model (some SDKs distinguish request vs response model)
 	"llm.response.model": "gen_ai.response.model",
 }
+
+// openInferenceMappings covers the scalar OpenInference span attributes.
+var openInferenceMappings = map[string]string{
+	"llm.provider":               "gen_ai.provider.name",
+	"llm.system":                 "gen_ai.system",
+	"llm.model_name":             "gen_ai.request.model",
+	"llm.token_count.prompt":     "gen_ai.usage.input_tokens",
+	"llm.token_count.completion": "gen_ai.usage.output_tokens",
+	"llm.token_count.total":      "gen_ai.usage.total_tokens",
+
+	"llm.token_count.prompt_details.cache_read":    "gen_ai.usage.cache_read.input_tokens",
+	"llm.token_count.prompt_details.cache_write":   "gen_ai.usage.cache_creation.input_tokens",
+	"llm.token_count.completion_details.reasoning": "gen_ai.usage.reasoning.output_tokens",
+}
+
+var openAIMappings = map[string]string{
+	"openai.model":             "gen_ai.request.model",
+	"openai.max_tokens":        "gen_ai.request.max_tokens",
+	"openai.temperature":       "gen_ai.request.temperature",
+	"openai.top_p":             "gen_ai.request.top_p",
+	"openai.prompt_tokens":     "gen_ai.usage.input_tokens",
+	"openai.completion_tokens": "gen_ai.usage.output_tokens",
+	"openai.total_tokens":      "gen_ai.usage.total_tokens",
+	"openai.finish_reason":     "gen_ai.response.finish_reasons",
+
+	"openai.prompt_tokens_details.cached_tokens":        "gen_ai.usage.cache_read.input_tokens",
+	"openai.completion_tokens_details.reasoning_tokens": "gen_ai.usage.reasoning.output_tokens",
+}
+
+var anthropicMappings = map[string]string{
+	"anthropic.model":         "gen_ai.request.model",
+	"anthropic.max_tokens":    "gen_ai.request.max_tokens",
+	"anthropic.input_tokens":  "gen_ai.usage.input_tokens",
+	"anthropic.output_tokens": "gen_ai.usage.output_tokens",
+	"anthropic.stop_reason":   "gen_ai.response.finish_reasons",
+
+	"anthropic.cache_read_input_tokens":     "gen_ai.usage.cache_read.input_tokens",
+	"anthropic.cache_creation_input_tokens": "gen_ai.usage.cache_creation.input_tokens",
+}
+
+var cohereMappings = map[string]string{
+	"cohere.model_id":        "gen_ai.request.model",
+	"cohere.prompt_tokens":   "gen_ai.usage.input_tokens",
+	"cohere.response_tokens": "gen_ai.usage.output_tokens",
+}
+
+// azureMappings covers Azure OpenAI.
+var azureMappings = map[string]string{
+	"az.ai.model":             "gen_ai.request.model",
+	"az.ai.prompt_tokens":     "gen_ai.usage.input_tokens",
+	"az.ai.completion_tokens": "gen_ai.usage.output_tokens",
+}
+
+// googleMappings covers Google / Vertex AI.
+var googleMappings = map[string]string{
+	"google.model":                  "gen_ai.request.model",
+	"google.prompt_token_count":     "gen_ai.usage.input_tokens",
+	"google.candidates_token_count": "gen_ai.usage.output_tokens",
+}
+
+// Metric name tables rename vendor metric names to the GenAI client metrics.
+// See: https://opentelemetry.io/docs/specs/semconv/gen-ai/gen-ai-metrics/
+var llmMetricMappings = map[string]string{
+	"llm.token.count":        "gen_ai.client.token.usage",
+	"llm.usage.tokens":       "gen_ai.client.token.usage",
+	"llm.request.duration":   "gen_ai.client.operation.duration",
+	"llm.operation.duration": "gen_ai.client.operation.duration",
+}
+
+var openAIMetricMappings = map[string]string{
+	"openai.tokens.used":      "gen_ai.client.token.usage",
+	"openai.request.duration": "gen_ai.client.operation.duration",
+}
+
+var anthropicMetricMappings = map[string]string{
+	"anthropic.tokens.used": "gen_ai.client.token.usage",
+}
+
+// systemPrefix infers gen_ai.system from a vendor-specific key prefix.
+type systemPrefix struct {
+	prefix string
+	system string
+}
@@ -10,6 +10,9 @@ import (
 
 const (
 	typeStr = "genai_semantic_normalizer"
+
+	// scopeName is the instrumentation scope of the processor's own telemetry.
+	scopeName = "github.com/nostalgicskinco/genai-semantic-normalizer/processor/genainormalizerprocessor"
 )
 
 // NewFactory creates a factory for the processor.
@@ -18,6 +21,8 @@ func NewFactory() processor.Factory {
 		component.MustNewType(typeStr),
 		func() component.Config { return createDefaultConfig() },
 		processor.WithTraces(createTracesProcessor, component.StabilityLevelDevelopment),
+		processor.WithMetrics(createMetricsProcessor, component.StabilityLevelDevelopment),
+		processor.WithLogs(createLogsProcessor, component.StabilityLevelDevelopment),
 	)
 }
 
@@ -30,3 +35,23 @@ func createTracesProcessor(
 	c := cfg.(*Config)
 	return newTracesProcessor(ctx, settings, c, next)
 }
+
+func createMetricsProcessor(
+	ctx context.Context,
+	settings processor.CreateSettings,
+	cfg component.Config,
+	next consumer.Metrics,
+) (processor.Metrics, error) {
+	c := cfg.(*Config)
+	return newMetricsProcessor(ctx, settings, c, next)
+}
+
+func createLogsProcessor(
+	ctx context.Context,
+	settings processor.CreateSettings,
+	cfg component.Config,
+	next consumer.Logs,
+) (processor.Logs, error) {
+	c := cfg.(*Config)
+	return newLogsProcessor(ctx, settings, c, next)
+}
@@ -0,0 +1,254 @@
+package genainormalizerprocessor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// mappingFile is the content of one mapping file. It uses the same keys as
+// the inline configuration; JSON files are read as YAML.
+type mappingFile struct {
+	path string
+
+	Mappings          map[string]string    `yaml:"mappings"`
+	Rules             []MappingRule        `yaml:"rules"`
+	MetricMappings    map[string]string    `yaml:"metric_mappings"`
+	Priorities        map[string][]string  `yaml:"priorities"`
+	Profiles          []string             `yaml:"profiles"`
+	ProfileConditions map[string]Condition `yaml:"profile_conditions"`
+}
+
+// readMappingFiles returns the raw contents of paths and their digest.
+func readMappingFiles(paths []string) ([][]byte, [sha256.Size]byte, error) {
+	contents := make([][]byte, 0, len(paths))
+	h := sha256.New()
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, [sha256.Size]byte{}, err
+		}
+		contents = append(contents, b)
+		h.Write(b)
+		h.Write([]byte{0})
+	}
+	var digest [sha256.Size]byte
+	copy(digest[:], h.Sum(nil))
+	return contents, digest, nil
+}
+
+// parseMappingFiles decodes the contents of paths. Unknown keys and profiles
+// are rejected with the offending file name.
+func parseMappingFiles(paths []string, contents [][]byte) ([]mappingFile, error) {
+	files := make([]mappingFile, 0, len(paths))
+	for i, path := range paths {
+		f := mappingFile{path: path}
+		dec := yaml.NewDecoder(bytes.NewReader(contents[i]))
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("mapping file %s: %w", path, err)
+		}
+		for _, name := range f.Profiles {
+			if _, ok := builtinProfiles[name]; !ok {
+				return nil, fmt.Errorf("mapping file %s: unknown profile %q (available: %s)", path, name, strings.Join(sortedKeys(builtinProfiles), ", "))
+			}
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// validate checks the mappings of f like Config.Validate does for the inline
+// ones, naming the file in every error.
+func (f mappingFile) validate(strict bool) error {
+	var errs []error
+	errs = append(errs, validateMap("mappings", f.Mappings, strict)...)
+	errs = append(errs, validateMap("metric_mappings", f.MetricMappings, strict)...)
+	errs = append(errs, validateRules(f.Rules, strict)...)
+	errs = append(errs, validatePriorities(f.Priorities)...)
+	errs = append(errs, validateProfileConditions(f.ProfileConditions)...)
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("mapping file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// liveNormalizer is the part of a processor shared by every signal. It holds
+// the current normalizer and, when mapping files or a price file are
+// configured, rebuilds it whenever their content changes. The swap is atomic:
+// each Consume call loads the normalizer once and uses it for the whole batch.
+type liveNormalizer struct {
+	logger   *zap.Logger
+	current  atomic.Pointer[normalizer]
+	paths    []string
+	interval time.Duration
+	build    func(files []mappingFile) (*normalizer, error)
+
+	// pricePath is the price file, watched with the mapping files.
+	pricePath string
+
+	// digest identifies the content of the loaded files; rejected
+	// the content that last failed to load, so it is reported only once.
+	digest   [sha256.Size]byte
+	rejected [sha256.Size]byte
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newLiveNormalizer(settings processor.CreateSettings, cfg *Config) (*liveNormalizer, error) {
+	if len(cfg.CustomMappings) > 0 || len(cfg.CustomMetricMappings) > 0 {
+		settings.Logger.Warn("custom_mappings and custom_metric_mappings are deprecated; use mappings and metric_mappings instead")
+	}
+
+	meterProvider := settings.MeterProvider
+	if meterProvider == nil {
+		meterProvider = noop.NewMeterProvider()
+	}
+	unknownAttrs, err := meterProvider.Meter(scopeName).Int64Counter(
+		"processor_genai_normalizer_unknown_attributes",
+		metric.WithDescription("Number of values written to gen_ai.* keys unknown to the semantic convention registry, or outside the enum members of a known key."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &liveNormalizer{
+		logger:    settings.Logger,
+		paths:     cfg.MappingFiles,
+		pricePath: cfg.Cost.PriceFile,
+		interval:  cfg.ReloadInterval,
+		build: func(files []mappingFile) (*normalizer, error) {
+			return newNormalizer(settings.Logger, cfg, files, unknownAttrs)
+		},
+	}
+
+	contents, digest, err := readMappingFiles(l.watched())
+	if err != nil {
+		return nil, err
+	}
+	n, err := l.load(contents)
+	if err != nil {
+		return nil, err
+	}
+	l.current.Store(n)
+	l.digest = digest
+	return l, nil
+}
+
+func (l *liveNormalizer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (l *liveNormalizer) Start(context.Context, component.Host) error {
+	n := l.current.Load()
+	l.logger.Info("genai_semantic_normalizer started",
+		zap.Int("mapping_count", len(n.mappings)),
+		zap.Strings("profiles", n.profileNames()),
+		zap.Strings("mapping_files", l.paths),
+		zap.String("price_file", l.pricePath),
+		zap.String("conflict_policy", n.conflictPolicy),
+		zap.Bool("overwrite", n.overwrite),
+		zap.Bool("drop_original", n.dropOriginal),
+	)
+
+	if len(l.watched()) == 0 || l.interval <= 0 {
+		return nil
+	}
+	l.stop = make(chan struct{})
+	l.wg.Add(1)
+	go l.watch()
+	return nil
+}
+
+func (l *liveNormalizer) Shutdown(context.Context) error {
+	if l.stop != nil {
+		close(l.stop)
+		l.wg.Wait()
+		l.stop = nil
+	}
+	return nil
+}
+
+// watched returns the mapping files followed by the price file, if any.
+func (l *liveNormalizer) watched() []string {
+	if l.pricePath == "" {
+		return l.paths
+	}
+	return append(append([]string(nil), l.paths...), l.pricePath)
+}
+
+// watch polls the mapping and price files until Shutdown.
+func (l *liveNormalizer) watch() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.reload(); err != nil {
+				l.logger.Warn("failed to reload mapping files; keeping the current mappings", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reload rebuilds the normalizer when the content of the mapping or price
+// files changed since they were last loaded.
+func (l *liveNormalizer) reload() error {
+	contents, digest, err := readMappingFiles(l.watched())
+	if err != nil {
+		return err
+	}
+	if digest == l.digest || digest == l.rejected {
+		return nil
+	}
+	n, err := l.load(contents)
+	if err != nil {
+		l.rejected = digest
+		return err
+	}
+	l.current.Store(n)
+	l.digest = digest
+	l.logger.Info("reloaded mapping files",
+		zap.Strings("mapping_files", l.paths),
+		zap.Int("mapping_count", len(n.mappings)),
+	)
+	return nil
+}
+
+// load builds a normalizer from the contents of the watched files.
+func (l *liveNormalizer) load(contents [][]byte) (*normalizer, error) {
+	files, err := parseMappingFiles(l.paths, contents[:len(l.paths)])
+	if err != nil {
+		return nil, err
+	}
+	n, err := l.build(files)
+	if err != nil {
+		return nil, err
+	}
+	if l.pricePath != "" {
+		if n.prices, err = parsePriceTable(l.pricePath, contents[len(l.paths)]); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,220 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func spanWith(attrs map[string]any) (ptrace.Traces, ptrace.Span) {
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	_ = sp.Attributes().FromRaw(attrs)
+	return td, sp
+}
+
+func TestMappingFilesMerge(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	writeFile(t, first, `
+mappings:
+  acme.model: gen_ai.request.model
+  acme.tokens: gen_ai.usage.input_tokens
+  acme.system: gen_ai.system
+metric_mappings:
+  acme.tokens.used: gen_ai.client.token.usage
+profiles: [anthropic]
+`)
+	second := filepath.Join(dir, "second.json")
+	writeFile(t, second, `{
+  "mappings": {"acme.tokens": "gen_ai.usage.output_tokens"},
+  "rules": [{"source": "acme.temp", "destination": "gen_ai.request.temperature", "type": "double"}]
+}`)
+
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.MappingFiles = []string{first, second}
+	cfg.Mappings = map[string]string{"acme.system": "gen_ai.provider.name"}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	td, sp := spanWith(map[string]any{
+		"acme.model":      "m1",
+		"acme.tokens":     5,
+		"acme.system":     "acme",
+		"acme.temp":       "0.5",
+		"anthropic.model": "claude-3-opus",
+	})
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	attrs := sp.Attributes()
+	assertHasStr(t, attrs, "gen_ai.request.model", "m1")
+	// Later files win over earlier ones, inline mappings over every file.
+	if v, ok := attrs.Get("gen_ai.usage.output_tokens"); !ok || v.Int() != 5 {
+		t.Fatalf("expected the second file to win, got %v", attrs.AsRaw())
+	}
+	if _, ok := attrs.Get("gen_ai.usage.input_tokens"); ok {
+		t.Fatalf("expected the first file's mapping to be overridden, got %v", attrs.AsRaw())
+	}
+	assertHasStr(t, attrs, "gen_ai.provider.name", "acme")
+	if v, _ := attrs.Get("gen_ai.request.temperature"); v.Double() != 0.5 {
+		t.Fatalf("expected rule from JSON file to apply, got %v", attrs.AsRaw())
+	}
+	// Profiles listed in a file are enabled.
+	assertHasStr(t, attrs, "gen_ai.system", "anthropic")
+
+	mp, err := newMetricsProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("acme.tokens.used")
+	if err := mp.ConsumeMetrics(context.Background(), md); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	if m.Name() != "gen_ai.client.token.usage" {
+		t.Fatalf("expected metric mapping from file, got %q", m.Name())
+	}
+}
+
+func TestMappingFileProfilesKeepDefaults(t *testing.T) {
+	cfg := createDefaultConfig()
+	n, err := newNormalizer(zap.NewNop(), cfg, []mappingFile{{Profiles: []string{ProfileVLLM}}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	for _, key := range []string{"openai.model", "anthropic.model"} {
+		if n.mappings[key] != "gen_ai.request.model" {
+			t.Errorf("expected %s to be mapped, got %q", key, n.mappings[key])
+		}
+	}
+}
+
+func TestMappingFilesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"unknown key":     "mapings:\n  a: b\n",
+		"unknown profile": "profiles: [nope]\n",
+		"bad rule":        "rules:\n  - source: a\n    destination: gen_ai.request.model\n    type: float\n",
+	}
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".yaml")
+			writeFile(t, path, content)
+			cfg := createDefaultConfig()
+			cfg.MappingFiles = []string{path}
+			_, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+			if err == nil || !strings.Contains(err.Error(), path) {
+				t.Fatalf("expected error naming %s, got %v", path, err)
+			}
+		})
+	}
+
+	cfg := createDefaultConfig()
+	cfg.MappingFiles = []string{filepath.Join(dir, "missing.yaml")}
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for missing mapping file")
+	}
+}
+
+func TestMappingFilesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mappings.yaml")
+	writeFile(t, path, "mappings:\n  acme.model: gen_ai.request.model\n")
+
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.MappingFiles = []string{path}
+	cfg.ReloadInterval = 0
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	before := p.current.Load()
+
+	// Unchanged content keeps the current normalizer.
+	if err := p.reload(); err != nil || p.current.Load() != before {
+		t.Fatalf("expected no reload for unchanged files, err=%v", err)
+	}
+
+	// Invalid content is rejected and the current normalizer kept.
+	writeFile(t, path, "mappings: [")
+	if err := p.reload(); err == nil {
+		t.Fatalf("expected error for invalid file")
+	}
+	if p.current.Load() != before {
+		t.Fatalf("expected the current normalizer to be kept")
+	}
+
+	writeFile(t, path, "mappings:\n  acme.model: gen_ai.response.model\n")
+	if err := p.reload(); err != nil {
+		t.Fatalf("reload err: %v", err)
+	}
+	td, sp := spanWith(map[string]any{"acme.model": "m1"})
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	assertHasStr(t, sp.Attributes(), "gen_ai.response.model", "m1")
+}
+
+func TestMappingFilesWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mappings.yaml")
+	writeFile(t, path, "mappings:\n  acme.model: gen_ai.request.model\n")
+
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.MappingFiles = []string{path}
+	cfg.ReloadInterval = 5 * time.Millisecond
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := p.Start(context.Background(), componenttest.NewNopHost()); err != nil {
+		t.Fatalf("start err: %v", err)
+	}
+	defer func() {
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Errorf("shutdown err: %v", err)
+		}
+	}()
+
+	writeFile(t, path, "mappings:\n  acme.model: gen_ai.response.model\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if p.current.Load().mappings["acme.model"] == "gen_ai.response.model" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("mapping files were not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
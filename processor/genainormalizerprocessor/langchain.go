@@ -0,0 +1,134 @@
+package genainormalizerprocessor
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// LangChain and LlamaIndex tracers record one span per callback run. The run
+// type tells LLM, embedding, agent and tool runs apart from chains,
+// retrievers, prompts and parsers, which are not GenAI operations. The model
+// call parameters are recorded as a JSON encoded invocation_params string.
+
+// langchainRunTypeKeys hold the run type, in lookup order.
+var langchainRunTypeKeys = []string{"langchain.run_type", "langsmith.span.kind", "llama_index.span_kind"}
+
+// langchainParamsKeys hold the JSON encoded invocation parameters, in lookup
+// order.
+var langchainParamsKeys = []string{"langchain.invocation_params", "invocation_params", "llama_index.invocation_params"}
+
+// langchainOperations maps the lower-cased run types of GenAI operations to
+// gen_ai.operation.name. Runs of any other type are left untouched.
+var langchainOperations = map[string]string{
+	"llm":           "chat",
+	"chat_model":    "chat",
+	"embedding":     "embeddings",
+	"agent":         "invoke_agent",
+	"agent_step":    "invoke_agent",
+	"tool":          "execute_tool",
+	"function_call": "execute_tool",
+}
+
+// langchainMappings covers the scalar LangChain and LlamaIndex attributes.
+var langchainMappings = map[string]string{
+	"langchain.request.model":           "gen_ai.request.model",
+	"langchain.request.temperature":     "gen_ai.request.temperature",
+	"langchain.request.top_p":           "gen_ai.request.top_p",
+	"langchain.request.max_tokens":      "gen_ai.request.max_tokens",
+	"langchain.response.model":          "gen_ai.response.model",
+	"langchain.usage.prompt_tokens":     "gen_ai.usage.input_tokens",
+	"langchain.usage.completion_tokens": "gen_ai.usage.output_tokens",
+	"langchain.usage.total_tokens":      "gen_ai.usage.total_tokens",
+	"llama_index.model":                 "gen_ai.request.model",
+	"llama_index.model_name":            "gen_ai.request.model",
+	"llama_index.temperature":           "gen_ai.request.temperature",
+	"llama_index.max_tokens":            "gen_ai.request.max_tokens",
+}
+
+// langchainParams lists the invocation_params fields, which follow the
+// parameter names of the LangChain model integrations.
+var langchainParams = []bodyField{
+	{"model", "gen_ai.request.model"},
+	{"model_name", "gen_ai.request.model"},
+	{"model_id", "gen_ai.request.model"},
+	{"deployment_name", "gen_ai.request.model"},
+	{"temperature", "gen_ai.request.temperature"},
+	{"top_p", "gen_ai.request.top_p"},
+	{"top_k", "gen_ai.request.top_k"},
+	{"max_tokens", "gen_ai.request.max_tokens"},
+	{"max_tokens_to_sample", "gen_ai.request.max_tokens"},
+	{"max_output_tokens", "gen_ai.request.max_tokens"},
+	{"stop", "gen_ai.request.stop_sequences"},
+	{"stop_sequences", "gen_ai.request.stop_sequences"},
+	{"n", "gen_ai.request.choice.count"},
+	{"seed", "gen_ai.request.seed"},
+	{"frequency_penalty", "gen_ai.request.frequency_penalty"},
+	{"presence_penalty", "gen_ai.request.presence_penalty"},
+}
+
+// langchainProviders maps prefixes of the invocation_params _type, the
+// LangChain integration name, to gen_ai.provider.name. Longer prefixes of
+// the same vendor come first.
+var langchainProviders = []struct {
+	prefix   string
+	provider string
+}{
+	{"azure", "azure.ai.openai"},
+	{"openai", "openai"},
+	{"anthropic", "anthropic"},
+	{"amazon_bedrock", "aws.bedrock"},
+	{"bedrock", "aws.bedrock"},
+	{"vertexai", "gcp.vertex_ai"},
+	{"google", "gcp.gen_ai"},
+	{"cohere", "cohere"},
+	{"mistral", "mistral_ai"},
+	{"groq", "groq"},
+}
+
+// langchainRunType returns the lower-cased run type of attrs, or "".
+func langchainRunType(attrs pcommon.Map) string {
+	for _, k := range langchainRunTypeKeys {
+		if v, ok := attrs.Get(k); ok {
+			return strings.ToLower(v.AsString())
+		}
+	}
+	return ""
+}
+
+// isLangChainGenAIRun reports whether attrs are not those of a non-GenAI run
+// such as a chain; attributes without a run type are accepted.
+func isLangChainGenAIRun(attrs pcommon.Map) bool {
+	runType := langchainRunType(attrs)
+	if runType == "" {
+		return true
+	}
+	_, ok := langchainOperations[runType]
+	return ok
+}
+
+// normalizeLangChain sets gen_ai.operation.name from the run type and
+// extracts the invocation parameters of a GenAI run.
+func (n *normalizer) normalizeLangChain(attrs pcommon.Map) {
+	if op, ok := langchainOperations[langchainRunType(attrs)]; ok {
+		n.put(attrs, "gen_ai.operation.name", pcommon.NewValueStr(op))
+	}
+
+	for _, k := range langchainParamsKeys {
+		params := decodeBody(attrs, k)
+		if params == nil {
+			continue
+		}
+		n.extractFields(attrs, params, langchainParams, map[string]bool{})
+		if typ, ok := lookupPath(params, "_type").(string); ok {
+			for _, p := range langchainProviders {
+				if strings.HasPrefix(typ, p.prefix) {
+					n.put(attrs, "gen_ai.provider.name", pcommon.NewValueStr(p.provider))
+					break
+				}
+			}
+		}
+		n.dropKeys(attrs, []string{k})
+		return
+	}
+}
@@ -0,0 +1,93 @@
+package genainormalizerprocessor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func langchainConfig() *Config {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileLangChain}
+	cfg.DropOriginal = true
+	return cfg
+}
+
+func TestLangChainProfile(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]any
+		want  map[string]any
+	}{
+		{
+			name: "llm run",
+			attrs: map[string]any{
+				"langchain.run_type":          "llm",
+				"langchain.invocation_params": `{"_type":"openai-chat","model_name":"gpt-4o","temperature":0.1,"stop":["END"],"n":2}`,
+			},
+			want: map[string]any{
+				"gen_ai.operation.name":         "chat",
+				"gen_ai.provider.name":          "openai",
+				"gen_ai.request.model":          "gpt-4o",
+				"gen_ai.request.temperature":    0.1,
+				"gen_ai.request.stop_sequences": []any{"END"},
+				"gen_ai.request.choice.count":   int64(2),
+			},
+		},
+		{
+			name: "azure chat model",
+			attrs: map[string]any{
+				"langsmith.span.kind":          "LLM",
+				"invocation_params":            `{"_type":"azure-openai-chat","deployment_name":"prod-gpt4"}`,
+				"langchain.usage.total_tokens": 42,
+			},
+			want: map[string]any{
+				"gen_ai.operation.name":     "chat",
+				"gen_ai.provider.name":      "azure.ai.openai",
+				"gen_ai.request.model":      "prod-gpt4",
+				"gen_ai.usage.total_tokens": int64(42),
+			},
+		},
+		{
+			name:  "embedding",
+			attrs: map[string]any{"langsmith.span.kind": "EMBEDDING", "llama_index.model_name": "text-embedding-3-small"},
+			want: map[string]any{
+				"gen_ai.operation.name": "embeddings",
+				"gen_ai.request.model":  "text-embedding-3-small",
+			},
+		},
+		{
+			name:  "tool",
+			attrs: map[string]any{"langchain.run_type": "tool"},
+			want:  map[string]any{"gen_ai.operation.name": "execute_tool"},
+		},
+		{
+			name:  "agent",
+			attrs: map[string]any{"llama_index.span_kind": "agent_step"},
+			want:  map[string]any{"gen_ai.operation.name": "invoke_agent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := consumeSpan(t, langchainConfig(), tt.attrs)
+			for k, want := range tt.want {
+				got, ok := attrs.Get(k)
+				if !ok || !reflect.DeepEqual(got.AsRaw(), want) {
+					t.Errorf("%s = %#v, want %#v", k, got.AsRaw(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestLangChainChainSpanUntouched(t *testing.T) {
+	in := map[string]any{
+		"langchain.run_type":          "chain",
+		"langchain.request.model":     "gpt-4o",
+		"langchain.invocation_params": `{"model":"gpt-4o"}`,
+	}
+	attrs := consumeSpan(t, langchainConfig(), in)
+	if got := attrs.AsRaw(); !reflect.DeepEqual(got, in) {
+		t.Fatalf("expected chain span to be untouched, got %v", got)
+	}
+}
@@ -0,0 +1,130 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+)
+
+// eventNameKey is the log record attribute carrying the event name in the
+// GenAI semantic convention event model.
+const eventNameKey = "event.name"
+
+type logsProcessor struct {
+	*liveNormalizer
+	next consumer.Logs
+}
+
+func newLogsProcessor(_ context.Context, settings processor.CreateSettings, cfg *Config, next consumer.Logs) (*logsProcessor, error) {
+	if next == nil {
+		return nil, fmt.Errorf("next consumer is nil")
+	}
+
+	l, err := newLiveNormalizer(settings, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logsProcessor{
+		liveNormalizer: l,
+		next:           next,
+	}, nil
+}
+
+func (p *logsProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	n := p.current.Load()
+	schemaURL := n.schemaURL()
+	rl := ld.ResourceLogs()
+	for i := 0; i < rl.Len(); i++ {
+		res := rl.At(i).Resource()
+		resource := res.Attributes()
+		n.normalizeAttributes(resource, TargetResource, resourceContext(res))
+		up := n.newUpHoister()
+		resourceGenAI := hasGenAIAttributes(resource)
+
+		sl := rl.At(i).ScopeLogs()
+		for j := 0; j < sl.Len(); j++ {
+			rc := recordContext{resource: res, scope: sl.At(j).Scope()}
+			n.normalizeAttributes(rc.scope.Attributes(), TargetScope, rc)
+			scopeGenAI := hasGenAIAttributes(rc.scope.Attributes())
+
+			records := sl.At(j).LogRecords()
+			normalize := func(record plog.LogRecord) []bool {
+				attrs := record.Attributes()
+				rc.time = record.Timestamp()
+				if rc.time == 0 {
+					rc.time = record.ObservedTimestamp()
+				}
+				active := n.normalizeRecord(attrs, TargetLog, rc)
+				n.mapEventName(attrs)
+				return active
+			}
+			observe := func(attrs pcommon.Map) {
+				n.hoistDown(resource, attrs)
+				up.observe(attrs)
+				scopeGenAI = scopeGenAI || hasGenAIAttributes(attrs)
+			}
+
+			// Records emitted by the profiles are normalized like their
+			// source and then inserted after it; ends[k] counts the records
+			// emitted up to records[k].
+			emitted := plog.NewLogRecordSlice()
+			ends := make([]int, records.Len())
+			for k := 0; k < records.Len(); k++ {
+				from := emitted.Len()
+				n.transformLog(records.At(k), emitted, normalize(records.At(k)))
+				observe(records.At(k).Attributes())
+				for e := from; e < emitted.Len(); e++ {
+					normalize(emitted.At(e))
+					observe(emitted.At(e).Attributes())
+				}
+				ends[k] = emitted.Len()
+			}
+			if emitted.Len() > 0 {
+				insertEmitted(records, emitted, ends)
+			}
+			if scopeGenAI && schemaURL != "" {
+				sl.At(j).SetSchemaUrl(schemaURL)
+				resourceGenAI = true
+			}
+		}
+		up.apply(resource)
+		if resourceGenAI && schemaURL != "" {
+			rl.At(i).SetSchemaUrl(schemaURL)
+		}
+	}
+
+	return p.next.ConsumeLogs(ctx, ld)
+}
+
+// insertEmitted moves every record of emitted after its source in records;
+// ends[k] is the number of records emitted up to records[k].
+func insertEmitted(records, emitted plog.LogRecordSlice, ends []int) {
+	ordered := plog.NewLogRecordSlice()
+	ordered.EnsureCapacity(records.Len() + emitted.Len())
+	e := 0
+	for k := 0; k < records.Len(); k++ {
+		records.At(k).MoveTo(ordered.AppendEmpty())
+		for ; e < ends[k]; e++ {
+			emitted.At(e).MoveTo(ordered.AppendEmpty())
+		}
+	}
+	records.RemoveIf(func(plog.LogRecord) bool { return true })
+	ordered.MoveAndAppendTo(records)
+}
+
+// mapEventName renames the event itself when its name is a mapped key, e.g. a
+// vendor event "llm.prompt" becomes "gen_ai.prompt".
+func (n *normalizer) mapEventName(attrs pcommon.Map) {
+	name, ok := attrs.Get(eventNameKey)
+	if !ok || name.Type() != pcommon.ValueTypeStr {
+		return
+	}
+	if dst, ok := n.mappings[name.Str()]; ok {
+		name.SetStr(dst)
+	}
+}
@@ -0,0 +1,309 @@
+package genainormalizerprocessor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+// Conflict policies decide which source wins when several source keys that
+// map to the same destination are present on one record.
+const (
+	// ConflictPolicyFirstWins uses the highest-priority source present.
+	ConflictPolicyFirstWins = "first_wins"
+	// ConflictPolicyLastWins uses the lowest-priority source present.
+	ConflictPolicyLastWins = "last_wins"
+	// ConflictPolicyPreferNonEmpty uses the highest-priority source whose
+	// value is not empty, falling back to first_wins.
+	ConflictPolicyPreferNonEmpty = "prefer_non_empty"
+	// ConflictPolicyErrorAttribute leaves the destination unset when the
+	// present sources disagree and records the destination key in
+	// conflictsAttr instead.
+	ConflictPolicyErrorAttribute = "error_attribute"
+)
+
+// conflictsAttr lists the destination keys that were not written because of
+// disagreeing sources under ConflictPolicyErrorAttribute.
+const conflictsAttr = "genai_normalizer.conflicts"
+
+// mappingEntry is one source -> destination mapping.
+type mappingEntry struct {
+	src  string
+	dst  string
+	conv converter
+	// targets restricts the entry to some attribute maps; empty means the
+	// configured default targets.
+	targets []string
+	// guards are indices into the normalizer guards; the entry applies to a
+	// record when any of them accepts it. Empty means always.
+	guards []int
+}
+
+// applies reports whether e applies given the guard results of a record.
+func (e mappingEntry) applies(active []bool) bool {
+	if len(e.guards) == 0 {
+		return true
+	}
+	for _, g := range e.guards {
+		if active[g] {
+			return true
+		}
+	}
+	return false
+}
+
+// destRule is every source feeding one destination key, in priority order.
+type destRule struct {
+	dst     string
+	sources []mappingEntry
+}
+
+// entriesFromMap returns the entries of a source -> destination map, sorted by
+// source key.
+func entriesFromMap(m map[string]string) []mappingEntry {
+	entries := make([]mappingEntry, 0, len(m))
+	for _, src := range sortedKeys(m) {
+		entries = append(entries, mappingEntry{src: src, dst: m[src]})
+	}
+	return entries
+}
+
+// entriesFromRules returns the entries of the long-form rules, in declared
+// order. The condition of a rule is appended to guards and referenced by the
+// entry.
+func entriesFromRules(logger *zap.Logger, rules []MappingRule, guards *[]guard) ([]mappingEntry, error) {
+	entries := make([]mappingEntry, 0, len(rules))
+	for i, r := range rules {
+		conv, err := newConverter(r.Type, r.OnFailure)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d] (%s): %w", i, r.Source, err)
+		}
+		if err := validateTargets(r.Targets); err != nil {
+			return nil, fmt.Errorf("rules[%d] (%s): %w", i, r.Source, err)
+		}
+		e := mappingEntry{src: r.Source, dst: r.Destination, conv: conv, targets: r.Targets}
+		cond, err := compileCondition(r.Condition, logger)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d] (%s): condition: %w", i, r.Source, err)
+		}
+		if cond != nil {
+			e.guards = []int{len(*guards)}
+			*guards = append(*guards, cond.match)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// compileRules builds the destination rules, sorted by destination key.
+//
+// Sources listed in priorities come first, in the listed order. The remaining
+// sources follow in table order (rules, mappings, custom_mappings, profiles),
+// so the result never depends on Go map iteration order.
+func compileRules(priorities map[string][]string, tables ...[]mappingEntry) []destRule {
+	// A source key maps to a single destination; earlier tables win.
+	bySrc := map[string]mappingEntry{}
+	var ordered []mappingEntry
+	for _, table := range tables {
+		for _, e := range table {
+			if _, claimed := bySrc[e.src]; claimed || isPattern(e.src) {
+				continue
+			}
+			bySrc[e.src] = e
+			ordered = append(ordered, e)
+		}
+	}
+
+	byDst := map[string][]mappingEntry{}
+	seen := map[string]map[string]bool{}
+	add := func(e mappingEntry) {
+		if seen[e.dst] == nil {
+			seen[e.dst] = map[string]bool{}
+		}
+		if seen[e.dst][e.src] {
+			return
+		}
+		seen[e.dst][e.src] = true
+		byDst[e.dst] = append(byDst[e.dst], e)
+	}
+
+	for _, dst := range sortedKeys(priorities) {
+		for _, src := range priorities[dst] {
+			e, ok := bySrc[src]
+			if !ok || e.dst != dst {
+				e = mappingEntry{src: src, dst: dst}
+			}
+			add(e)
+		}
+	}
+	for _, e := range ordered {
+		add(e)
+	}
+
+	rules := make([]destRule, 0, len(byDst))
+	for _, dst := range sortedKeys(byDst) {
+		rules = append(rules, destRule{dst: dst, sources: byDst[dst]})
+	}
+	return rules
+}
+
+func validateConflictPolicy(policy string) error {
+	switch policy {
+	case "", ConflictPolicyFirstWins, ConflictPolicyLastWins, ConflictPolicyPreferNonEmpty, ConflictPolicyErrorAttribute:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict_policy %q", policy)
+}
+
+// applyMappings applies the rules compiled for target to attrs. active holds
+// the guard results of the record, evaluated before it was modified.
+func (n *normalizer) applyMappings(attrs pcommon.Map, target string, active []bool) {
+	set := n.sets[target]
+	// Iterate over rules instead of attributes to avoid iterator invalidation
+	// when deleting keys.
+	for _, r := range set.rules {
+		n.resolve(attrs, r.dst, r.sources, active)
+	}
+	n.applyPatterns(attrs, set.patterns, active)
+}
+
+// resolve writes dst from the present sources, in priority order.
+func (n *normalizer) resolve(attrs pcommon.Map, dst string, sources []mappingEntry, active []bool) {
+	var present []mappingEntry
+	for _, e := range sources {
+		if e.src == dst {
+			continue
+		}
+		if !e.applies(active) {
+			continue
+		}
+		if _, ok := attrs.Get(e.src); ok {
+			present = append(present, e)
+		}
+	}
+	if len(present) == 0 {
+		return
+	}
+
+	if _, exists := attrs.Get(dst); exists && !n.overwrite {
+		// Destination already present and overwrite disabled.
+		return
+	}
+
+	winner, ok := n.pickSource(attrs, present)
+	if !ok {
+		appendStr(attrs, conflictsAttr, dst)
+		return
+	}
+
+	val, _ := attrs.Get(winner.src)
+	n.writeDest(attrs, dst, winner.conv, val)
+
+	// Optionally drop every original that fed this destination.
+	if n.dropOriginal {
+		for _, e := range present {
+			attrs.Remove(e.src)
+		}
+	}
+}
+
+// destConverter returns the converter for values written to dst: conv when it
+// declares a type, the semantic convention type of dst otherwise. Writes to
+// unknown gen_ai.* keys are reported.
+func (n *normalizer) destConverter(dst string, conv converter) converter {
+	if regConv, known := semconvConverter(dst); !known && isGenAIKey(dst) {
+		n.reportUnknown(dst)
+	} else if conv.typ == "" && n.semconvTypes {
+		conv = regConv
+	}
+	return conv
+}
+
+// put writes val to dst, coerced to its semantic convention type, unless dst
+// is present and overwrite is disabled. It is used by profiles deriving
+// values that have no single source key.
+func (n *normalizer) put(attrs pcommon.Map, dst string, val pcommon.Value) {
+	if _, exists := attrs.Get(dst); exists && !n.overwrite {
+		return
+	}
+	n.writeDest(attrs, dst, converter{}, val)
+}
+
+// writeDest writes val to dst through destConverter and reports string values
+// outside the enum members of dst, and deprecated keys that no target
+// version will migrate.
+func (n *normalizer) writeDest(attrs pcommon.Map, dst string, conv converter, val pcommon.Value) {
+	n.destConverter(dst, conv).write(attrs, dst, val)
+	def, ok := semconvRegistry[dst]
+	if !ok {
+		return
+	}
+	if v, ok := attrs.Get(dst); ok && v.Type() == pcommon.ValueTypeStr && def.outsideEnum(v.Str()) {
+		n.reportUnknownValue(dst, v.Str())
+	}
+	if def.stability == stabilityDeprecated && n.migrator == nil {
+		n.logger.Debug("wrote deprecated gen_ai attribute; set target_semconv_version to migrate it", zap.String("key", dst))
+	}
+}
+
+// pickSource selects the winning source among present according to the
+// conflict policy. It returns false when the policy refuses to choose.
+func (n *normalizer) pickSource(attrs pcommon.Map, present []mappingEntry) (mappingEntry, bool) {
+	switch n.conflictPolicy {
+	case ConflictPolicyLastWins:
+		return present[len(present)-1], true
+	case ConflictPolicyPreferNonEmpty:
+		for _, e := range present {
+			if val, _ := attrs.Get(e.src); !isEmptyValue(val) {
+				return e, true
+			}
+		}
+	case ConflictPolicyErrorAttribute:
+		first, _ := attrs.Get(present[0].src)
+		for _, e := range present[1:] {
+			if val, _ := attrs.Get(e.src); !reflect.DeepEqual(first.AsRaw(), val.AsRaw()) {
+				return mappingEntry{}, false
+			}
+		}
+	}
+	return present[0], true
+}
+
+// appendStr appends s to the string slice attribute key, creating it if needed.
+func appendStr(attrs pcommon.Map, key, s string) {
+	var slice pcommon.Slice
+	if v, ok := attrs.Get(key); ok && v.Type() == pcommon.ValueTypeSlice {
+		slice = v.Slice()
+	} else {
+		slice = attrs.PutEmptySlice(key)
+	}
+	slice.AppendEmpty().SetStr(s)
+}
+
+func isEmptyValue(v pcommon.Value) bool {
+	switch v.Type() {
+	case pcommon.ValueTypeEmpty:
+		return true
+	case pcommon.ValueTypeStr:
+		return v.Str() == ""
+	case pcommon.ValueTypeSlice:
+		return v.Slice().Len() == 0
+	case pcommon.ValueTypeMap:
+		return v.Map().Len() == 0
+	case pcommon.ValueTypeBytes:
+		return v.Bytes().Len() == 0
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
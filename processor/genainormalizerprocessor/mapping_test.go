@@ -0,0 +1,152 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestConflictPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		model    string
+		name2    string
+		want     string
+		conflict bool
+	}{
+		{name: "first_wins", policy: ConflictPolicyFirstWins, model: "gpt-4o", name2: "gpt-4o-mini", want: "gpt-4o-mini"},
+		{name: "last_wins", policy: ConflictPolicyLastWins, model: "gpt-4o", name2: "gpt-4o-mini", want: "gpt-4o"},
+		{name: "prefer_non_empty", policy: ConflictPolicyPreferNonEmpty, model: "gpt-4o", name2: "", want: "gpt-4o"},
+		{name: "error_attribute agree", policy: ConflictPolicyErrorAttribute, model: "gpt-4o", name2: "gpt-4o", want: "gpt-4o"},
+		{name: "error_attribute disagree", policy: ConflictPolicyErrorAttribute, model: "gpt-4o", name2: "gpt-4o-mini", conflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+			cfg := createDefaultConfig()
+			cfg.Overwrite = true
+			cfg.ConflictPolicy = tt.policy
+			cfg.Priorities = map[string][]string{
+				"gen_ai.request.model": {"llm.model_name", "llm.model"},
+			}
+
+			p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			// Run several times: the outcome must not depend on map iteration order.
+			for i := 0; i < 20; i++ {
+				td := ptrace.NewTraces()
+				sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+				sp.Attributes().PutStr("llm.model", tt.model)
+				sp.Attributes().PutStr("llm.model_name", tt.name2)
+
+				if err := p.ConsumeTraces(context.Background(), td); err != nil {
+					t.Fatalf("consume err: %v", err)
+				}
+
+				if tt.conflict {
+					if _, ok := sp.Attributes().Get("gen_ai.request.model"); ok {
+						t.Fatalf("expected gen_ai.request.model to be left unset")
+					}
+					v, ok := sp.Attributes().Get(conflictsAttr)
+					if !ok || v.Slice().Len() != 1 || v.Slice().At(0).Str() != "gen_ai.request.model" {
+						t.Fatalf("expected %s=[gen_ai.request.model], got %v", conflictsAttr, v.AsRaw())
+					}
+					continue
+				}
+				assertHasStr(t, sp.Attributes(), "gen_ai.request.model", tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRulesOrder(t *testing.T) {
+	rules := compileRules(
+		map[string][]string{"gen_ai.request.model": {"openai.model"}},
+		entriesFromMap(map[string]string{"my.model": "gen_ai.request.model"}),
+		entriesFromMap(map[string]string{"llm.model_name": "gen_ai.request.model", "llm.model": "gen_ai.request.model", "my.model": "other"}),
+	)
+
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+	want := []string{"openai.model", "my.model", "llm.model", "llm.model_name"}
+	var got []string
+	for _, e := range rules[0].sources {
+		got = append(got, e.src)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+}
+
+func TestUnknownConflictPolicy(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.ConflictPolicy = "random"
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for unknown conflict_policy")
+	}
+}
+
+func TestPatternMappings(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.DropOriginal = true
+	cfg.Mappings = map[string]string{
+		"llm.input_messages.*.message.*":                   "gen_ai.prompt.$1.$2",
+		`regex:^llm\.output_messages\.(?P<idx>\d+)\.role$`: "gen_ai.completion.${idx}.role",
+		"vendor.**.model":                                  "gen_ai.request.model",
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("llm.input_messages.0.message.role", "system")
+	sp.Attributes().PutStr("llm.input_messages.1.message.content", "hello")
+	sp.Attributes().PutStr("llm.output_messages.0.role", "assistant")
+	sp.Attributes().PutStr("vendor.a.b.model", "gpt-4o")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	attrs := sp.Attributes()
+	assertHasStr(t, attrs, "gen_ai.prompt.0.role", "system")
+	assertHasStr(t, attrs, "gen_ai.prompt.1.content", "hello")
+	assertHasStr(t, attrs, "gen_ai.completion.0.role", "assistant")
+	assertHasStr(t, attrs, "gen_ai.request.model", "gpt-4o")
+	if _, ok := attrs.Get("llm.input_messages.0.message.role"); ok {
+		t.Fatalf("expected matched source to be dropped")
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Mappings = map[string]string{"regex:llm.(": "gen_ai.x"}
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for invalid regex source")
+	}
+}
@@ -0,0 +1,372 @@
+package genainormalizerprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// GenAI event names of the semantic convention event model.
+// See: https://opentelemetry.io/docs/specs/semconv/gen-ai/gen-ai-events/
+const (
+	systemMessageEvent    = "gen_ai.system.message"
+	userMessageEvent      = "gen_ai.user.message"
+	assistantMessageEvent = "gen_ai.assistant.message"
+	toolMessageEvent      = "gen_ai.tool.message"
+	choiceEvent           = "gen_ai.choice"
+)
+
+// Message output modes.
+const (
+	// MessageOutputEvents records messages as GenAI events: span events on
+	// traces and log records on logs.
+	MessageOutputEvents = "events"
+	// MessageOutputAttributes records messages as the JSON encoded
+	// gen_ai.input.messages and gen_ai.output.messages attributes.
+	MessageOutputAttributes = "attributes"
+	// MessageOutputBoth records messages both ways.
+	MessageOutputBoth = "both"
+)
+
+const (
+	inputMessagesAttr  = "gen_ai.input.messages"
+	outputMessagesAttr = "gen_ai.output.messages"
+	finishReasonsAttr  = "gen_ai.response.finish_reasons"
+)
+
+func validateMessageOutput(mode string) error {
+	switch mode {
+	case "", MessageOutputEvents, MessageOutputAttributes, MessageOutputBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown message_output %q", mode)
+}
+
+// genaiMessage is one chat message reassembled from flattened, indexed
+// attributes such as llm.input_messages.0.message.content.
+type genaiMessage struct {
+	index        int
+	role         string
+	content      string
+	toolCallID   string
+	toolCalls    []toolCall
+	finishReason string
+	// parts holds multi-part text content by part index; it is joined into
+	// content when content itself is not set.
+	parts map[int]string
+}
+
+type toolCall struct {
+	index     int
+	id        string
+	name      string
+	arguments string
+}
+
+// messageField sets the field of m addressed by path, the part of the key
+// after the message index. It reports whether path was recognized.
+type messageField func(m *genaiMessage, path string, v pcommon.Value) bool
+
+// collectMessages reassembles the messages whose keys start with prefix
+// followed by an index, ordered by index. It also returns the consumed keys.
+func collectMessages(attrs pcommon.Map, prefix string, field messageField) ([]genaiMessage, []string) {
+	byIndex := map[int]*genaiMessage{}
+	var keys []string
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			return true
+		}
+		idx, path, ok := splitIndex(rest)
+		if !ok {
+			return true
+		}
+		m := byIndex[idx]
+		if m == nil {
+			m = &genaiMessage{index: idx}
+		}
+		if field(m, path, v) {
+			byIndex[idx] = m
+			keys = append(keys, k)
+		}
+		return true
+	})
+
+	indices := make([]int, 0, len(byIndex))
+	for idx := range byIndex {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+	msgs := make([]genaiMessage, 0, len(indices))
+	for _, idx := range indices {
+		m := byIndex[idx]
+		if m.content == "" && len(m.parts) > 0 {
+			m.content = joinParts(m.parts)
+		}
+		sort.Slice(m.toolCalls, func(i, j int) bool { return m.toolCalls[i].index < m.toolCalls[j].index })
+		msgs = append(msgs, *m)
+	}
+	return msgs, keys
+}
+
+// splitIndex splits "3.message.role" into 3 and "message.role".
+func splitIndex(s string) (int, string, bool) {
+	head, rest, _ := strings.Cut(s, ".")
+	idx, err := strconv.Atoi(head)
+	if err != nil || idx < 0 {
+		return 0, "", false
+	}
+	return idx, rest, true
+}
+
+func (m *genaiMessage) addPart(idx int, text string) {
+	if m.parts == nil {
+		m.parts = map[int]string{}
+	}
+	m.parts[idx] = text
+}
+
+func joinParts(parts map[int]string) string {
+	indices := make([]int, 0, len(parts))
+	for idx := range parts {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+	var b strings.Builder
+	for _, idx := range indices {
+		b.WriteString(parts[idx])
+	}
+	return b.String()
+}
+
+// toolCallAt returns the tool call with the given index, adding it if needed.
+func (m *genaiMessage) toolCallAt(idx int) *toolCall {
+	for i := range m.toolCalls {
+		if m.toolCalls[i].index == idx {
+			return &m.toolCalls[i]
+		}
+	}
+	m.toolCalls = append(m.toolCalls, toolCall{index: idx})
+	return &m.toolCalls[len(m.toolCalls)-1]
+}
+
+// inputEventName returns the event name for an input message of role.
+func inputEventName(role string) string {
+	switch role {
+	case "system", "developer":
+		return systemMessageEvent
+	case "assistant", "model":
+		return assistantMessageEvent
+	case "tool", "function":
+		return toolMessageEvent
+	}
+	return userMessageEvent
+}
+
+// inputBody fills body with the event body of an input message.
+func (m genaiMessage) inputBody(body pcommon.Map) {
+	if m.role != "" {
+		body.PutStr("role", m.role)
+	}
+	if m.content != "" {
+		body.PutStr("content", m.content)
+	}
+	if m.toolCallID != "" {
+		body.PutStr("id", m.toolCallID)
+	}
+	m.putToolCalls(body)
+}
+
+// choiceBody fills body with the event body of an output message.
+func (m genaiMessage) choiceBody(body pcommon.Map) {
+	body.PutInt("index", int64(m.index))
+	finish := m.finishReason
+	if finish == "" {
+		finish = "unknown"
+	}
+	body.PutStr("finish_reason", finish)
+
+	msg := body.PutEmptyMap("message")
+	if m.role != "" {
+		msg.PutStr("role", m.role)
+	}
+	if m.content != "" {
+		msg.PutStr("content", m.content)
+	}
+	m.putToolCalls(msg)
+}
+
+func (m genaiMessage) putToolCalls(dst pcommon.Map) {
+	if len(m.toolCalls) == 0 {
+		return
+	}
+	calls := dst.PutEmptySlice("tool_calls")
+	for _, tc := range m.toolCalls {
+		call := calls.AppendEmpty().SetEmptyMap()
+		if tc.id != "" {
+			call.PutStr("id", tc.id)
+		}
+		call.PutStr("type", "function")
+		fn := call.PutEmptyMap("function")
+		fn.PutStr("name", tc.name)
+		if tc.arguments != "" {
+			fn.PutStr("arguments", tc.arguments)
+		}
+	}
+}
+
+// messageParts returns the parts of m in the gen_ai.input.messages and
+// gen_ai.output.messages schema.
+func (m genaiMessage) messageParts() []any {
+	var parts []any
+	if m.content != "" {
+		if m.toolCallID != "" {
+			parts = append(parts, map[string]any{"type": "tool_call_response", "id": m.toolCallID, "response": m.content})
+		} else {
+			parts = append(parts, map[string]any{"type": "text", "content": m.content})
+		}
+	}
+	for _, tc := range m.toolCalls {
+		part := map[string]any{"type": "tool_call", "name": tc.name}
+		if tc.id != "" {
+			part["id"] = tc.id
+		}
+		if tc.arguments != "" {
+			var args any
+			if err := json.Unmarshal([]byte(tc.arguments), &args); err != nil {
+				args = tc.arguments
+			}
+			part["arguments"] = args
+		}
+		parts = append(parts, part)
+	}
+	if parts == nil {
+		parts = []any{}
+	}
+	return parts
+}
+
+// encodeMessages returns msgs as a JSON message list. Output messages carry
+// their finish reason.
+func encodeMessages(msgs []genaiMessage, output bool) (string, error) {
+	list := make([]map[string]any, 0, len(msgs))
+	for _, m := range msgs {
+		role := m.role
+		if role == "" {
+			role = "user"
+			if output {
+				role = "assistant"
+			}
+		}
+		entry := map[string]any{"role": role, "parts": m.messageParts()}
+		if output {
+			finish := m.finishReason
+			if finish == "" {
+				finish = "unknown"
+			}
+			entry["finish_reason"] = finish
+		}
+		list = append(list, entry)
+	}
+	b, err := json.Marshal(list)
+	return string(b), err
+}
+
+// putMessageAttributes writes the gen_ai.input.messages and
+// gen_ai.output.messages attributes, leaving existing ones untouched unless
+// overwrite is set.
+func putMessageAttributes(attrs pcommon.Map, inputs, outputs []genaiMessage, overwrite bool) {
+	put := func(key string, msgs []genaiMessage, output bool) {
+		if len(msgs) == 0 {
+			return
+		}
+		if _, exists := attrs.Get(key); exists && !overwrite {
+			return
+		}
+		if s, err := encodeMessages(msgs, output); err == nil {
+			attrs.PutStr(key, s)
+		}
+	}
+	put(inputMessagesAttr, inputs, false)
+	put(outputMessagesAttr, outputs, true)
+}
+
+// putFinishReasons collects the finish reasons of outputs, in choice order,
+// into gen_ai.response.finish_reasons.
+func putFinishReasons(attrs pcommon.Map, outputs []genaiMessage, overwrite bool) {
+	var reasons []string
+	for _, m := range outputs {
+		if m.finishReason != "" {
+			reasons = append(reasons, m.finishReason)
+		}
+	}
+	if len(reasons) == 0 {
+		return
+	}
+	if _, exists := attrs.Get(finishReasonsAttr); exists && !overwrite {
+		return
+	}
+	s := attrs.PutEmptySlice(finishReasonsAttr)
+	for _, r := range reasons {
+		s.AppendEmpty().SetStr(r)
+	}
+}
+
+// eventAttributeKeys are copied from the originating record to every emitted
+// event so the events can be attributed to a provider.
+var eventAttributeKeys = []string{"gen_ai.system", "gen_ai.provider.name"}
+
+// appendMessageSpanEvents records inputs and outputs as span events. The
+// event body fields become event attributes.
+func appendMessageSpanEvents(span ptrace.Span, inputs, outputs []genaiMessage) {
+	for _, m := range inputs {
+		ev := span.Events().AppendEmpty()
+		ev.SetName(inputEventName(m.role))
+		ev.SetTimestamp(span.StartTimestamp())
+		copyEventAttributes(span.Attributes(), ev.Attributes())
+		m.inputBody(ev.Attributes())
+	}
+	for _, m := range outputs {
+		ev := span.Events().AppendEmpty()
+		ev.SetName(choiceEvent)
+		ev.SetTimestamp(span.EndTimestamp())
+		copyEventAttributes(span.Attributes(), ev.Attributes())
+		m.choiceBody(ev.Attributes())
+	}
+}
+
+// appendMessageLogRecords records inputs and outputs as GenAI event log
+// records correlated with record.
+func appendMessageLogRecords(record plog.LogRecord, out plog.LogRecordSlice, inputs, outputs []genaiMessage) {
+	emit := func(name string, fill func(pcommon.Map)) {
+		lr := out.AppendEmpty()
+		lr.SetTimestamp(record.Timestamp())
+		lr.SetObservedTimestamp(record.ObservedTimestamp())
+		lr.SetTraceID(record.TraceID())
+		lr.SetSpanID(record.SpanID())
+		lr.SetFlags(record.Flags())
+		lr.Attributes().PutStr(eventNameKey, name)
+		copyEventAttributes(record.Attributes(), lr.Attributes())
+		fill(lr.Body().SetEmptyMap())
+	}
+	for _, m := range inputs {
+		emit(inputEventName(m.role), m.inputBody)
+	}
+	for _, m := range outputs {
+		emit(choiceEvent, m.choiceBody)
+	}
+}
+
+func copyEventAttributes(src, dst pcommon.Map) {
+	for _, k := range eventAttributeKeys {
+		if v, ok := src.Get(k); ok {
+			v.CopyTo(dst.PutEmpty(k))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor"
+)
+
+type metricsProcessor struct {
+	*liveNormalizer
+	next consumer.Metrics
+}
+
+func newMetricsProcessor(_ context.Context, settings processor.CreateSettings, cfg *Config, next consumer.Metrics) (*metricsProcessor, error) {
+	if next == nil {
+		return nil, fmt.Errorf("next consumer is nil")
+	}
+
+	l, err := newLiveNormalizer(settings, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metricsProcessor{
+		liveNormalizer: l,
+		next:           next,
+	}, nil
+}
+
+func (p *metricsProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	n := p.current.Load()
+	schemaURL := n.schemaURL()
+	rm := md.ResourceMetrics()
+	for i := 0; i < rm.Len(); i++ {
+		res := rm.At(i).Resource()
+		n.normalizeAttributes(res.Attributes(), TargetResource, resourceContext(res))
+		resourceGenAI := hasGenAIAttributes(res.Attributes())
+
+		sm := rm.At(i).ScopeMetrics()
+		for j := 0; j < sm.Len(); j++ {
+			rc := recordContext{resource: res, scope: sm.At(j).Scope()}
+			n.normalizeAttributes(rc.scope.Attributes(), TargetScope, rc)
+			scopeGenAI := hasGenAIAttributes(rc.scope.Attributes())
+			metrics := sm.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if dst, ok := n.metricMappings[metric.Name()]; ok {
+					metric.SetName(dst)
+				}
+				if n.applyDataPointMappings(metric, rc) || isGenAIKey(metric.Name()) {
+					scopeGenAI = true
+				}
+			}
+			if scopeGenAI && schemaURL != "" {
+				sm.At(j).SetSchemaUrl(schemaURL)
+				resourceGenAI = true
+			}
+		}
+		if resourceGenAI && schemaURL != "" {
+			rm.At(i).SetSchemaUrl(schemaURL)
+		}
+	}
+
+	return p.next.ConsumeMetrics(ctx, md)
+}
+
+// applyDataPointMappings normalizes the data points of metric and reports
+// whether any of them carries GenAI attributes.
+func (n *normalizer) applyDataPointMappings(metric pmetric.Metric, rc recordContext) bool {
+	genAI := false
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			n.normalizeRecord(dps.At(i).Attributes(), TargetDataPoint, rc)
+			genAI = hasGenAIAttributes(dps.At(i).Attributes()) || genAI
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			n.normalizeRecord(dps.At(i).Attributes(), TargetDataPoint, rc)
+			genAI = hasGenAIAttributes(dps.At(i).Attributes()) || genAI
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			n.normalizeRecord(dps.At(i).Attributes(), TargetDataPoint, rc)
+			genAI = hasGenAIAttributes(dps.At(i).Attributes()) || genAI
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			n.normalizeRecord(dps.At(i).Attributes(), TargetDataPoint, rc)
+			genAI = hasGenAIAttributes(dps.At(i).Attributes()) || genAI
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			n.normalizeRecord(dps.At(i).Attributes(), TargetDataPoint, rc)
+			genAI = hasGenAIAttributes(dps.At(i).Attributes()) || genAI
+		}
+	}
+	return genAI
+}
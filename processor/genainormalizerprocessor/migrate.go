@@ -0,0 +1,188 @@
+package genainormalizerprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// semconvVersions lists the versions accepted by target_semconv_version,
+// oldest first.
+var semconvVersions = []string{
+	"1.26.0", "1.27.0", "1.28.0", "1.29.0", "1.30.0", "1.31.0",
+	"1.32.0", "1.33.0", "1.34.0", "1.35.0", "1.36.0", "1.37.0",
+}
+
+// schemaURLPrefix is prepended to the target version to form the schema URL.
+const schemaURLPrefix = "https://opentelemetry.io/schemas/"
+
+// keyMigration renames a GenAI attribute key as of a semconv version. Values
+// maps old enum members to new ones across the same change.
+type keyMigration struct {
+	from   string
+	to     string
+	since  string
+	values map[string]string
+	// convert rewrites the value when moving from the old to the new key.
+	convert func(pcommon.Value)
+}
+
+var keyMigrations = []keyMigration{
+	{from: "gen_ai.usage.prompt_tokens", to: "gen_ai.usage.input_tokens", since: "1.27.0"},
+	{from: "gen_ai.usage.completion_tokens", to: "gen_ai.usage.output_tokens", since: "1.27.0"},
+	{from: "gen_ai.openai.request.seed", to: "gen_ai.request.seed", since: "1.27.0"},
+	{from: "gen_ai.system", to: "gen_ai.provider.name", since: "1.37.0", values: map[string]string{
+		"az.ai.inference": "azure.ai.inference",
+		"az.ai.openai":    "azure.ai.openai",
+		"gemini":          "gcp.gemini",
+		"vertex_ai":       "gcp.vertex_ai",
+		"xai":             "x_ai",
+	}},
+	{from: "gen_ai.prompt", to: "gen_ai.input.messages", since: "1.37.0", convert: messagesConverter("user")},
+	{from: "gen_ai.completion", to: "gen_ai.output.messages", since: "1.37.0", convert: messagesConverter("assistant")},
+	{from: "gen_ai.openai.request.service_tier", to: "openai.request.service_tier", since: "1.37.0"},
+	{from: "gen_ai.openai.response.service_tier", to: "openai.response.service_tier", since: "1.37.0"},
+	{from: "gen_ai.openai.response.system_fingerprint", to: "openai.response.system_fingerprint", since: "1.37.0"},
+}
+
+// migrator rewrites keys and enum values to a target semconv version. Keys
+// introduced after the target are rewritten back to their older spelling.
+type migrator struct {
+	version   string
+	schemaURL string
+	// steps holds, for each migration, the direction to apply.
+	steps []migrationStep
+
+	// dualEmit keeps writing the other spelling of every migrated key next
+	// to the target one, except for the keys in sunset.
+	dualEmit bool
+	sunset   map[string]bool
+}
+
+type migrationStep struct {
+	from    string
+	to      string
+	values  map[string]string
+	back    map[string]string
+	convert func(pcommon.Value)
+}
+
+// newMigrator returns nil when neither a target version nor dual emission is
+// configured. Dual emission without a target version targets the latest one.
+func newMigrator(version string, dual DualEmitConfig) (*migrator, error) {
+	if version == "" {
+		if !dual.Enabled {
+			return nil, nil
+		}
+		version = semconvVersions[len(semconvVersions)-1]
+	}
+	target := versionIndex(version)
+	if target < 0 {
+		return nil, fmt.Errorf("unsupported target_semconv_version %q (supported: %s)", version, strings.Join(semconvVersions, ", "))
+	}
+
+	m := &migrator{
+		version:   version,
+		schemaURL: schemaURLPrefix + version,
+		dualEmit:  dual.Enabled,
+		sunset:    map[string]bool{},
+	}
+	for _, key := range dual.Sunset {
+		m.sunset[key] = true
+	}
+	for _, km := range keyMigrations {
+		back := make(map[string]string, len(km.values))
+		for old, cur := range km.values {
+			back[cur] = old
+		}
+		if target >= versionIndex(km.since) {
+			m.steps = append(m.steps, migrationStep{from: km.from, to: km.to, values: km.values, back: back, convert: km.convert})
+			continue
+		}
+		m.steps = append(m.steps, migrationStep{from: km.to, to: km.from, values: back, back: km.values})
+	}
+	return m, nil
+}
+
+func versionIndex(version string) int {
+	for i, v := range semconvVersions {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// usesProviderName reports whether the target version names the provider
+// gen_ai.provider.name rather than gen_ai.system.
+func (m *migrator) usesProviderName() bool {
+	return m != nil && versionIndex(m.version) >= versionIndex("1.37.0")
+}
+
+// migrate rewrites deprecated keys and enum values in attrs. When both the
+// old and the new key are present, the new key is kept unless overwrite is
+// set. With dual emission, the other spelling is kept, or derived from the
+// target key when absent.
+func (m *migrator) migrate(attrs pcommon.Map, overwrite bool) {
+	if m == nil {
+		return
+	}
+	for _, st := range m.steps {
+		dual := m.dualEmit && !m.sunset[st.from]
+
+		val, ok := attrs.Get(st.from)
+		if ok {
+			if _, exists := attrs.Get(st.to); !exists || overwrite {
+				dst := attrs.PutEmpty(st.to)
+				val.CopyTo(dst)
+				if st.convert != nil {
+					st.convert(dst)
+				}
+			}
+			if !dual {
+				attrs.Remove(st.from)
+			}
+		}
+
+		cur, ok := attrs.Get(st.to)
+		if !ok {
+			continue
+		}
+		if cur.Type() == pcommon.ValueTypeStr {
+			if v, ok := st.values[cur.Str()]; ok {
+				cur.SetStr(v)
+			}
+		}
+		if _, exists := attrs.Get(st.from); dual && !exists {
+			legacy := attrs.PutEmpty(st.from)
+			cur.CopyTo(legacy)
+			if legacy.Type() == pcommon.ValueTypeStr {
+				if v, ok := st.back[legacy.Str()]; ok {
+					legacy.SetStr(v)
+				}
+			}
+		}
+	}
+}
+
+// messagesConverter wraps a plain-text gen_ai.prompt/gen_ai.completion value
+// into the JSON message list expected by gen_ai.input.messages and
+// gen_ai.output.messages. Values that already are JSON arrays are kept.
+func messagesConverter(role string) func(pcommon.Value) {
+	return func(v pcommon.Value) {
+		if v.Type() != pcommon.ValueTypeStr || strings.HasPrefix(strings.TrimSpace(v.Str()), "[") {
+			return
+		}
+		msg := []map[string]any{{
+			"role":  role,
+			"parts": []map[string]any{{"type": "text", "content": v.Str()}},
+		}}
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return
+		}
+		v.SetStr(string(b))
+	}
+}
@@ -0,0 +1,180 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestMigrateToProviderName(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.TargetSemconvVersion = "1.37.0"
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	ss := rs.ScopeSpans().AppendEmpty()
+	spans := ss.Spans()
+
+	azure := spans.AppendEmpty()
+	azure.Attributes().PutStr("az.ai.model", "gpt-4o")
+
+	legacy := spans.AppendEmpty()
+	legacy.Attributes().PutStr("gen_ai.system", "vertex_ai")
+	legacy.Attributes().PutInt("gen_ai.usage.prompt_tokens", 12)
+	legacy.Attributes().PutStr("gen_ai.prompt", "hi")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, azure.Attributes(), "gen_ai.provider.name", "azure.ai.openai")
+	if _, ok := azure.Attributes().Get("gen_ai.system"); ok {
+		t.Fatalf("expected gen_ai.system to be migrated away")
+	}
+
+	attrs := legacy.Attributes()
+	assertHasStr(t, attrs, "gen_ai.provider.name", "gcp.vertex_ai")
+	if v, ok := attrs.Get("gen_ai.usage.input_tokens"); !ok || v.Int() != 12 {
+		t.Fatalf("expected gen_ai.usage.input_tokens=12, got %v", v.AsRaw())
+	}
+	assertHasStr(t, attrs, "gen_ai.input.messages", `[{"parts":[{"content":"hi","type":"text"}],"role":"user"}]`)
+	for _, k := range []string{"gen_ai.system", "gen_ai.usage.prompt_tokens", "gen_ai.prompt"} {
+		if _, ok := attrs.Get(k); ok {
+			t.Fatalf("expected %s to be migrated away", k)
+		}
+	}
+
+	if rs.SchemaUrl() != "https://opentelemetry.io/schemas/1.37.0" || ss.SchemaUrl() != rs.SchemaUrl() {
+		t.Fatalf("unexpected schema urls %q / %q", rs.SchemaUrl(), ss.SchemaUrl())
+	}
+}
+
+func TestSchemaURLOnlyOnGenAIData(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.TargetSemconvVersion = "1.37.0"
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	const other = "https://opentelemetry.io/schemas/1.21.0"
+	td := ptrace.NewTraces()
+	http := td.ResourceSpans().AppendEmpty()
+	http.SetSchemaUrl(other)
+	httpScope := http.ScopeSpans().AppendEmpty()
+	httpScope.SetSchemaUrl(other)
+	httpScope.Spans().AppendEmpty().Attributes().PutStr("http.request.method", "GET")
+
+	genai := td.ResourceSpans().AppendEmpty()
+	genai.SetSchemaUrl(other)
+	plain := genai.ScopeSpans().AppendEmpty()
+	plain.SetSchemaUrl(other)
+	plain.Spans().AppendEmpty().Attributes().PutStr("db.system", "redis")
+	llm := genai.ScopeSpans().AppendEmpty()
+	llm.Spans().AppendEmpty().Attributes().PutStr("llm.model", "gpt-4o")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	const want = "https://opentelemetry.io/schemas/1.37.0"
+	for name, got := range map[string]string{
+		"non-GenAI resource": http.SchemaUrl(),
+		"non-GenAI scope":    httpScope.SchemaUrl(),
+		"plain scope":        plain.SchemaUrl(),
+	} {
+		if got != other {
+			t.Errorf("%s: expected schema url %q to be kept, got %q", name, other, got)
+		}
+	}
+	if genai.SchemaUrl() != want || llm.SchemaUrl() != want {
+		t.Fatalf("unexpected schema urls %q / %q", genai.SchemaUrl(), llm.SchemaUrl())
+	}
+}
+
+func TestMigrateToOlderVersion(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.TargetSemconvVersion = "1.27.0"
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("llm.provider", "azure.ai.openai")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, sp.Attributes(), "gen_ai.system", "az.ai.openai")
+	if _, ok := sp.Attributes().Get("gen_ai.provider.name"); ok {
+		t.Fatalf("expected gen_ai.provider.name to be rewritten for 1.27.0")
+	}
+}
+
+func TestUnsupportedSemconvVersion(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.TargetSemconvVersion = "2.0.0"
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for unsupported target_semconv_version")
+	}
+}
+
+func TestDualEmit(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.DualEmit = DualEmitConfig{
+		Enabled: true,
+		Sunset:  []string{"gen_ai.usage.completion_tokens"},
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("google.model", "gemini-1.5-pro")
+	sp.Attributes().PutInt("gen_ai.usage.prompt_tokens", 10)
+	sp.Attributes().PutInt("gen_ai.usage.completion_tokens", 20)
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	attrs := sp.Attributes()
+	assertHasStr(t, attrs, "gen_ai.provider.name", "gcp.vertex_ai")
+	assertHasStr(t, attrs, "gen_ai.system", "vertex_ai")
+	if v, ok := attrs.Get("gen_ai.usage.input_tokens"); !ok || v.Int() != 10 {
+		t.Fatalf("expected gen_ai.usage.input_tokens=10, got %v", v.AsRaw())
+	}
+	if v, ok := attrs.Get("gen_ai.usage.prompt_tokens"); !ok || v.Int() != 10 {
+		t.Fatalf("expected gen_ai.usage.prompt_tokens=10, got %v", v.AsRaw())
+	}
+	if v, ok := attrs.Get("gen_ai.usage.output_tokens"); !ok || v.Int() != 20 {
+		t.Fatalf("expected gen_ai.usage.output_tokens=20, got %v", v.AsRaw())
+	}
+	if _, ok := attrs.Get("gen_ai.usage.completion_tokens"); ok {
+		t.Fatalf("expected sunset key gen_ai.usage.completion_tokens to be removed")
+	}
+}
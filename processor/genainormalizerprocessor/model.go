@@ -0,0 +1,90 @@
+package genainormalizerprocessor
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// defaultModelFamilyAttr receives the canonical model name unless
+// model_family.attribute says otherwise.
+const defaultModelFamilyAttr = "gen_ai.request.model.family"
+
+// defaultModelRules canonicalize model names: provider, region and vendor
+// prefixes are stripped first, along with the Bedrock version of vendor
+// prefixed ids, then version and date suffixes, so that
+//
+//	gpt-4o-2024-08-06, azure/gpt-4o              -> gpt-4o
+//	models/gemini-1.5-pro-002                    -> gemini-1.5-pro
+//	us.anthropic.claude-3-sonnet-20240229-v1:0   -> claude-3-sonnet
+var defaultModelRules = []ModelRule{
+	// Provider and API path prefixes.
+	{Pattern: `^(?:azure|azure_ai|openai|anthropic|bedrock|vertex_ai|gemini|google|groq|mistral|ollama)/`},
+	{Pattern: `^(?:projects/[^/]+/locations/[^/]+/)?(?:publishers/[^/]+/)?models/`},
+	// Bedrock model versions (-v1:0) of vendor-prefixed model ids. Titan and
+	// Claude 2 ids carry the model generation in -vN, so only :M is dropped.
+	{Pattern: `^((?:(?:us|eu|apac|us-gov)\.)?(?:amazon\.titan-[\w.-]+|anthropic\.claude)-v\d+):\d+$`, Replacement: "${1}"},
+	{Pattern: `^((?:(?:us|eu|apac|us-gov)\.)?(?:anthropic|amazon|meta|cohere|mistral|ai21|stability|deepseek|writer)\.[\w.-]+)-v\d+:\d+$`, Replacement: "${1}"},
+	// Bedrock cross-region inference profiles and model vendors.
+	{Pattern: `^(?:us|eu|apac|us-gov)\.`},
+	{Pattern: `^(?:anthropic|amazon|meta|cohere|mistral|ai21|stability|deepseek|writer)\.`},
+	// Vertex AI versions (@20240620).
+	{Pattern: `@[\w.-]+$`},
+	// Release dates (-2024-08-06, -20240229, -12-2024).
+	{Pattern: `-(?:\d{4}-\d{2}-\d{2}|\d{8}|\d{2}-\d{4})$`},
+	// Short release dates and versions, only for the families that use them:
+	// OpenAI snapshots (-0613), Mistral releases (-2402) and Gemini versions
+	// (-002). Elsewhere such suffixes name distinct models, as in
+	// text-embedding-ada-002 or llama3-70b-8192.
+	{Pattern: `^((?:gpt-|chatgpt-|o\d)[\w.-]*?)-\d{4}$`, Replacement: "${1}"},
+	{Pattern: `^((?:mistral|ministral|codestral|pixtral|open-mistral|open-mixtral)[\w.-]*?)-\d{4}$`, Replacement: "${1}"},
+	{Pattern: `^(gemini-[\w.-]*?)-\d{3}$`, Replacement: "${1}"},
+	{Pattern: `-latest$`},
+}
+
+// modelRule is a compiled ModelRule.
+type modelRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// compileModelRules compiles the configured rules followed, unless disabled,
+// by the default ones.
+func compileModelRules(cfg ModelFamilyConfig) ([]modelRule, error) {
+	rules := cfg.Rules
+	if !cfg.DisableDefaultRules {
+		rules = append(append([]ModelRule(nil), rules...), defaultModelRules...)
+	}
+	compiled := make([]modelRule, 0, len(rules))
+	for i, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("model_family.rules[%d]: invalid pattern %q: %w", i, r.Pattern, err)
+		}
+		compiled = append(compiled, modelRule{re: re, replacement: r.Replacement})
+	}
+	return compiled, nil
+}
+
+// canonicalModel applies every rule, in order, to model.
+func canonicalModel(rules []modelRule, model string) string {
+	for _, r := range rules {
+		model = r.re.ReplaceAllString(model, r.replacement)
+	}
+	return model
+}
+
+// putModelFamily writes the canonical name of the request model, or of the
+// response model when the request model is unknown, to the family attribute.
+// The model attributes are left as they are.
+func (n *normalizer) putModelFamily(attrs pcommon.Map) {
+	for _, key := range []string{"gen_ai.request.model", "gen_ai.response.model"} {
+		if v, ok := attrs.Get(key); ok && v.Type() == pcommon.ValueTypeStr && v.Str() != "" {
+			if family := canonicalModel(n.modelRules, v.Str()); family != "" {
+				n.put(attrs, n.modelFamilyAttr, pcommon.NewValueStr(family))
+			}
+			return
+		}
+	}
+}
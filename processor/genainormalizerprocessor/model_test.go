@@ -0,0 +1,73 @@
+package genainormalizerprocessor
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalModel(t *testing.T) {
+	rules, err := compileModelRules(ModelFamilyConfig{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	tests := map[string]string{
+		"gpt-4o-2024-08-06":         "gpt-4o",
+		"gpt-4o":                    "gpt-4o",
+		"azure/gpt-4o":              "gpt-4o",
+		"gpt-3.5-turbo-0125":        "gpt-3.5-turbo",
+		"models/gemini-1.5-pro-002": "gemini-1.5-pro",
+		"publishers/google/models/gemini-1.5-flash":   "gemini-1.5-flash",
+		"anthropic.claude-3-sonnet-20240229-v1:0":     "claude-3-sonnet",
+		"us.anthropic.claude-3-5-haiku-20241022-v1:0": "claude-3-5-haiku",
+		"claude-3-5-sonnet@20240620":                  "claude-3-5-sonnet",
+		"claude-3-opus-latest":                        "claude-3-opus",
+		"amazon.titan-text-express-v1":                "titan-text-express-v1",
+		"amazon.titan-embed-text-v1":                  "titan-embed-text-v1",
+		"amazon.titan-embed-text-v2:0":                "titan-embed-text-v2",
+		"anthropic.claude-v2:1":                       "claude-v2",
+		"meta.llama3-70b-instruct-v1:0":               "llama3-70b-instruct",
+		"amazon.nova-pro-v1:0":                        "nova-pro",
+		"deepseek-v3":                                 "deepseek-v3",
+		"deepseek-v2":                                 "deepseek-v2",
+		"command-r7b-12-2024":                         "command-r7b",
+		"command-r-08-2024":                           "command-r",
+		"mistral.mistral-large-2402-v1:0":             "mistral-large",
+		"llama3.1:8b":                                 "llama3.1:8b",
+		"gpt-4-0613":                                  "gpt-4",
+		"mistral-large-2402":                          "mistral-large",
+		"text-embedding-ada-002":                      "text-embedding-ada-002",
+		"llama3-70b-8192":                             "llama3-70b-8192",
+	}
+	for in, want := range tests {
+		if got := canonicalModel(rules, in); got != want {
+			t.Errorf("canonicalModel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestModelFamily(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	writeFile(t, path, "currency: USD\nprices:\n  - model: gpt-4o\n    input: 2.5\n    output: 10\n")
+
+	cfg := createDefaultConfig()
+	cfg.Cost.PriceFile = path
+	cfg.ModelFamily = ModelFamilyConfig{
+		Enabled: true,
+		Rules:   []ModelRule{{Pattern: `^prod-chat-(\w+)$`, Replacement: "gpt-$1"}},
+	}
+
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"openai.model":         "azure/gpt-4o",
+		"openai.prompt_tokens": 1000,
+	})
+	assertHasStr(t, attrs, "gen_ai.request.model", "azure/gpt-4o")
+	assertHasStr(t, attrs, defaultModelFamilyAttr, "gpt-4o")
+	// The price is found through the family.
+	if v, ok := attrs.Get(costAttr); !ok || v.Double() != 0.0025 {
+		t.Fatalf("expected cost from the family price, got %v", attrs.AsRaw())
+	}
+
+	cfg.ModelFamily.Attribute = "llm.model_family"
+	attrs = consumeSpan(t, cfg, map[string]any{"gen_ai.response.model": "prod-chat-4o"})
+	assertHasStr(t, attrs, "llm.model_family", "gpt-4o")
+}
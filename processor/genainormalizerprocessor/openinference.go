@@ -0,0 +1,87 @@
+package genainormalizerprocessor
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// OpenInference flattens chat messages into indexed span attributes:
+//
+//	llm.input_messages.0.message.role = "user"
+//	llm.input_messages.0.message.content = "hello"
+//	llm.output_messages.0.message.tool_calls.0.tool_call.function.name = "search"
+//
+// See: https://github.com/Arize-ai/openinference/blob/main/spec/semantic_conventions.md
+const (
+	openInferenceInputPrefix  = "llm.input_messages."
+	openInferenceOutputPrefix = "llm.output_messages."
+)
+
+// openInferenceMessages reassembles the OpenInference input and output
+// messages of attrs and returns the flattened keys they were built from.
+func openInferenceMessages(attrs pcommon.Map) (inputs, outputs []genaiMessage, keys []string) {
+	inputs, inKeys := collectMessages(attrs, openInferenceInputPrefix, openInferenceField)
+	outputs, outKeys := collectMessages(attrs, openInferenceOutputPrefix, openInferenceField)
+	return inputs, outputs, append(inKeys, outKeys...)
+}
+
+func openInferenceField(m *genaiMessage, path string, v pcommon.Value) bool {
+	path, ok := strings.CutPrefix(path, "message.")
+	if !ok {
+		return false
+	}
+
+	switch path {
+	case "role":
+		m.role = v.AsString()
+		return true
+	case "content":
+		m.content = v.AsString()
+		return true
+	case "tool_call_id":
+		m.toolCallID = v.AsString()
+		return true
+	case "finish_reason":
+		m.finishReason = v.AsString()
+		return true
+	}
+
+	// message.contents.N.message_content.text holds multi-part content.
+	if rest, ok := strings.CutPrefix(path, "contents."); ok {
+		idx, field, ok := splitIndex(rest)
+		if !ok {
+			return false
+		}
+		// Only text parts are extracted; other parts, such as images, are
+		// left in place. The type of a text part is implied by its text.
+		switch {
+		case field == "message_content.text":
+			m.addPart(idx, v.AsString())
+		case field == "message_content.type" && v.AsString() == "text":
+		default:
+			return false
+		}
+		return true
+	}
+
+	// message.tool_calls.N.tool_call.{id,function.name,function.arguments}
+	if rest, ok := strings.CutPrefix(path, "tool_calls."); ok {
+		idx, field, ok := splitIndex(rest)
+		if !ok {
+			return false
+		}
+		switch field {
+		case "tool_call.id":
+			m.toolCallAt(idx).id = v.AsString()
+		case "tool_call.function.name":
+			m.toolCallAt(idx).name = v.AsString()
+		case "tool_call.function.arguments":
+			m.toolCallAt(idx).arguments = v.AsString()
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestOpenInferenceSpanEvents(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.DropOriginal = true
+	cfg.Profiles = []string{ProfileOpenInference}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	attrs := sp.Attributes()
+	attrs.PutStr("llm.provider", "openai")
+	attrs.PutStr("llm.input_messages.1.message.role", "user")
+	attrs.PutStr("llm.input_messages.1.message.contents.1.message_content.text", "world")
+	attrs.PutStr("llm.input_messages.1.message.contents.0.message_content.text", "hello ")
+	attrs.PutStr("llm.input_messages.0.message.role", "system")
+	attrs.PutStr("llm.input_messages.0.message.content", "be brief")
+	attrs.PutStr("llm.output_messages.0.message.role", "assistant")
+	attrs.PutStr("llm.output_messages.0.message.tool_calls.0.tool_call.id", "call_1")
+	attrs.PutStr("llm.output_messages.0.message.tool_calls.0.tool_call.function.name", "search")
+	attrs.PutStr("llm.output_messages.0.message.tool_calls.0.tool_call.function.arguments", `{"q":"otel"}`)
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	events := sp.Events()
+	if events.Len() != 3 {
+		t.Fatalf("expected 3 events, got %d", events.Len())
+	}
+
+	sys := events.At(0)
+	if sys.Name() != "gen_ai.system.message" {
+		t.Fatalf("unexpected event %q", sys.Name())
+	}
+	assertHasStr(t, sys.Attributes(), "content", "be brief")
+	assertHasStr(t, sys.Attributes(), "gen_ai.provider.name", "openai")
+
+	user := events.At(1)
+	if user.Name() != "gen_ai.user.message" {
+		t.Fatalf("unexpected event %q", user.Name())
+	}
+	assertHasStr(t, user.Attributes(), "content", "hello world")
+
+	choice := events.At(2)
+	if choice.Name() != "gen_ai.choice" {
+		t.Fatalf("unexpected event %q", choice.Name())
+	}
+	want := map[string]any{
+		"role": "assistant",
+		"tool_calls": []any{map[string]any{
+			"id":       "call_1",
+			"type":     "function",
+			"function": map[string]any{"name": "search", "arguments": `{"q":"otel"}`},
+		}},
+	}
+	if msg, _ := choice.Attributes().Get("message"); !reflect.DeepEqual(msg.AsRaw(), want) {
+		t.Fatalf("unexpected choice message %v", msg.AsRaw())
+	}
+
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		if len(k) > 4 && k[:4] == "llm." {
+			t.Fatalf("expected flattened key %q to be dropped", k)
+		}
+		return true
+	})
+}
+
+func TestOpenInferenceUnknownToolCallFields(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileOpenInference}
+
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	attrs := sp.Attributes()
+	attrs.PutStr("llm.output_messages.0.message.role", "assistant")
+	attrs.PutStr("llm.output_messages.0.message.tool_calls.0.tool_call.id", "call_1")
+	attrs.PutStr("llm.output_messages.0.message.tool_calls.0.tool_call.function.name", "search")
+	attrs.PutStr("llm.output_messages.0.message.tool_calls.1.tool_call.vendor.trace_id", "abc")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	if sp.Events().Len() != 1 {
+		t.Fatalf("expected 1 event, got %d", sp.Events().Len())
+	}
+	want := map[string]any{
+		"role": "assistant",
+		"tool_calls": []any{map[string]any{
+			"id":       "call_1",
+			"type":     "function",
+			"function": map[string]any{"name": "search"},
+		}},
+	}
+	if msg, _ := sp.Events().At(0).Attributes().Get("message"); !reflect.DeepEqual(msg.AsRaw(), want) {
+		t.Fatalf("unexpected choice message %v", msg.AsRaw())
+	}
+}
+
+func TestOpenInferenceKeepsImageParts(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileOpenInference}
+	cfg.DropOriginal = true
+
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"llm.input_messages.0.message.role":                                       "user",
+		"llm.input_messages.0.message.contents.0.message_content.type":            "text",
+		"llm.input_messages.0.message.contents.0.message_content.text":            "what is this?",
+		"llm.input_messages.0.message.contents.1.message_content.type":            "image",
+		"llm.input_messages.0.message.contents.1.message_content.image.image.url": "https://example.com/cat.png",
+	})
+
+	for _, key := range []string{
+		"llm.input_messages.0.message.contents.1.message_content.type",
+		"llm.input_messages.0.message.contents.1.message_content.image.image.url",
+	} {
+		if _, ok := attrs.Get(key); !ok {
+			t.Errorf("expected %s to be kept, got %v", key, attrs.AsRaw())
+		}
+	}
+	for _, key := range []string{
+		"llm.input_messages.0.message.contents.0.message_content.type",
+		"llm.input_messages.0.message.contents.0.message_content.text",
+	} {
+		if _, ok := attrs.Get(key); ok {
+			t.Errorf("expected %s to be dropped, got %v", key, attrs.AsRaw())
+		}
+	}
+}
+
+func TestOpenInferenceLogRecords(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileOpenInference}
+
+	p, err := newLogsProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lr := records.AppendEmpty()
+	lr.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	lr.Attributes().PutStr("llm.input_messages.0.message.role", "user")
+	lr.Attributes().PutStr("llm.input_messages.0.message.content", "hi")
+	lr.Attributes().PutStr("llm.output_messages.0.message.role", "assistant")
+	lr.Attributes().PutStr("llm.output_messages.0.message.content", "hello")
+
+	if err := p.ConsumeLogs(context.Background(), ld); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	// Emitted records are inserted after their source, which is moved.
+	lr = records.At(0)
+
+	if records.Len() != 3 {
+		t.Fatalf("expected 2 emitted records, got %d total", records.Len())
+	}
+	user := records.At(1)
+	assertHasStr(t, user.Attributes(), "event.name", "gen_ai.user.message")
+	assertHasStr(t, user.Body().Map(), "content", "hi")
+	if user.SpanID() != lr.SpanID() {
+		t.Fatalf("expected emitted record to keep the span id")
+	}
+	choice := records.At(2)
+	assertHasStr(t, choice.Attributes(), "event.name", "gen_ai.choice")
+	if idx, _ := choice.Body().Map().Get("index"); idx.Int() != 0 {
+		t.Fatalf("unexpected choice index %v", idx.AsRaw())
+	}
+	// Without drop_original the flattened keys are kept.
+	assertHasStr(t, lr.Attributes(), "llm.input_messages.0.message.content", "hi")
+}
+
+func TestOpenInferenceLogRecordOrder(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileOpenInference}
+
+	p, err := newLogsProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, content := range []string{"first", "second"} {
+		lr := records.AppendEmpty()
+		lr.Attributes().PutStr("llm.provider", "openai")
+		lr.Attributes().PutStr("llm.input_messages.0.message.role", "user")
+		lr.Attributes().PutStr("llm.input_messages.0.message.content", content)
+	}
+
+	if err := p.ConsumeLogs(context.Background(), ld); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	if records.Len() != 4 {
+		t.Fatalf("expected 4 records, got %d", records.Len())
+	}
+	for i, content := range []string{"first", "second"} {
+		source, user := records.At(2*i), records.At(2*i+1)
+		assertHasStr(t, source.Attributes(), "llm.input_messages.0.message.content", content)
+		assertHasStr(t, user.Attributes(), "event.name", "gen_ai.user.message")
+		assertHasStr(t, user.Body().Map(), "content", content)
+		assertHasStr(t, user.Attributes(), "gen_ai.provider.name", "openai")
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{"nope"}
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
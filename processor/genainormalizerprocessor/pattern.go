@@ -0,0 +1,131 @@
+package genainormalizerprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// regexPrefix marks a mapping source key as an RE2 pattern. Source keys
+// containing '*' are globs: '*' matches within one dot-separated segment and
+// '**' matches across segments. Every glob wildcard is a capture group.
+//
+// Captures are substituted into the destination with $1, ${1} or ${name}:
+//
+//	llm.input_messages.*.message.role: gen_ai.prompt.$1.role
+//	"regex:^llm\.output_messages\.(\d+)\.message\.(\w+)$": gen_ai.completion.$1.$2
+const regexPrefix = "regex:"
+
+// patternRule maps every attribute key matching re to the expansion of its
+// entry's destination.
+type patternRule struct {
+	mappingEntry
+	re *regexp.Regexp
+}
+
+func isPattern(src string) bool {
+	return strings.HasPrefix(src, regexPrefix) || strings.Contains(src, "*")
+}
+
+// compilePatterns compiles the pattern sources of tables, in the same origin
+// and key order used for exact sources.
+func compilePatterns(tables ...[]mappingEntry) ([]patternRule, error) {
+	var patterns []patternRule
+	claimed := map[string]bool{}
+	for _, table := range tables {
+		for _, e := range table {
+			if !isPattern(e.src) || claimed[e.src] {
+				continue
+			}
+			claimed[e.src] = true
+
+			re, err := compilePattern(e.src)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mapping source %q: %w", e.src, err)
+			}
+			patterns = append(patterns, patternRule{mappingEntry: e, re: re})
+		}
+	}
+	return patterns, nil
+}
+
+func compilePattern(src string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(src, regexPrefix); ok {
+		return regexp.Compile(expr)
+	}
+	return regexp.Compile(globToRegex(src))
+}
+
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		if glob[i] != '*' {
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			continue
+		}
+		if i+1 < len(glob) && glob[i+1] == '*' {
+			b.WriteString("(.+)")
+			i++
+			continue
+		}
+		b.WriteString(`([^.]+)`)
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// expand returns the destination for key, or false when key does not match.
+func (r patternRule) expand(key string) (string, bool) {
+	m := r.re.FindStringSubmatchIndex(key)
+	if m == nil {
+		return "", false
+	}
+	return string(r.re.ExpandString(nil, r.dst, key, m)), true
+}
+
+// applyPatterns maps keys matching pattern sources. A key is claimed by the
+// first pattern it matches; keys expanding to the same destination are
+// resolved like exact sources, in pattern order and then key order.
+func (n *normalizer) applyPatterns(attrs pcommon.Map, patterns []patternRule, active []bool) {
+	if len(patterns) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, attrs.Len())
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+
+	var dsts []string
+	sources := map[string][]mappingEntry{}
+	claimed := map[string]bool{}
+	for _, p := range patterns {
+		if !p.applies(active) {
+			continue
+		}
+		for _, key := range keys {
+			if claimed[key] {
+				continue
+			}
+			dst, ok := p.expand(key)
+			if !ok {
+				continue
+			}
+			claimed[key] = true
+			if _, ok := sources[dst]; !ok {
+				dsts = append(dsts, dst)
+			}
+			sources[dst] = append(sources[dst], mappingEntry{src: key, dst: dst, conv: p.conv})
+		}
+	}
+
+	for _, dst := range dsts {
+		n.resolve(attrs, dst, sources[dst], active)
+	}
+}
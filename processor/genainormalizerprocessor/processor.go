@@ -4,99 +4,375 @@ import (
 	"context"
 	"fmt"
 
-	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
-type tracesProcessor struct {
+// normalizer holds the mapping table and options shared by every signal. It
+// is immutable once built; see liveNormalizer for reloading.
+type normalizer struct {
 	logger *zap.Logger
-	next   consumer.Traces
 
-	overwrite    bool
-	dropOriginal bool
-	mappings     map[string]string
+	overwrite      bool
+	dropOriginal   bool
+	conflictPolicy string
+
+	// mappings is the merged source -> destination table.
+	mappings map[string]string
+	// metricMappings is the merged metric name table.
+	metricMappings map[string]string
+	// sets holds the compiled rules for each target.
+	sets map[string]ruleSet
+	// hoist copies keys between the resource and GenAI records.
+	hoist HoistConfig
+	// profiles are the enabled profiles, in configured order.
+	profiles []profile
+	// guards are the profile guards and the rule and profile conditions,
+	// evaluated once per record before it is modified; mapping entries refer
+	// to them by index.
+	guards []guard
+	// systems infer gen_ai.system from key prefixes of the enabled profiles.
+	systems []systemPrefix
+	// messageOutput selects how profiles record reassembled messages.
+	messageOutput string
+	// deriveUsage fills and reconciles the token counts of spans and log
+	// records.
+	deriveUsage bool
+	// prices estimates the cost of spans and log records; nil disables cost
+	// estimation. batchAttr marks batch requests.
+	prices    *priceTable
+	batchAttr string
+	// modelRules canonicalize model names for price lookups and, when
+	// modelFamilyAttr is set, into that attribute.
+	modelRules      []modelRule
+	modelFamilyAttr string
+
+	// semconvTypes coerces values written to known gen_ai.* keys to their
+	// registry type when the mapping declares none.
+	semconvTypes bool
+	// unknownAttrs counts writes to gen_ai.* keys missing from the registry.
+	unknownAttrs metric.Int64Counter
+
+	// migrator rewrites deprecated keys to target_semconv_version; nil
+	// disables migration.
+	migrator *migrator
 }
 
-func newTracesProcessor(_ context.Context, settings processor.CreateSettings, cfg *Config, next consumer.Traces) (*tracesProcessor, error) {
-	if next == nil {
-		return nil, fmt.Errorf("next consumer is nil")
+// newNormalizer compiles cfg, merged with the mapping files, into a
+// normalizer. Inline settings win over the files, and later files over
+// earlier ones.
+func newNormalizer(logger *zap.Logger, cfg *Config, files []mappingFile, unknownAttrs metric.Int64Counter) (*normalizer, error) {
+	if err := validateConflictPolicy(cfg.ConflictPolicy); err != nil {
+		return nil, err
+	}
+
+	if err := validateMessageOutput(cfg.MessageOutput); err != nil {
+		return nil, err
+	}
+
+	profiles, err := enabledProfiles(cfg, files)
+	if err != nil {
+		return nil, err
+	}
+	conditions := map[string]Condition{}
+	for _, f := range files {
+		for name, c := range f.ProfileConditions {
+			conditions[name] = c
+		}
+	}
+	for name, c := range cfg.ProfileConditions {
+		conditions[name] = c
+	}
+	for i := range profiles {
+		if profiles[i].condition, err = compileCondition(conditions[profiles[i].name], logger); err != nil {
+			return nil, fmt.Errorf("profile_conditions[%q]: %w", profiles[i].name, err)
+		}
+	}
+	guards := profileGuards(profiles)
+	rules, err := entriesFromRules(logger, cfg.Rules, &guards)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := [][]mappingEntry{
+		rules,
+		entriesFromMap(cfg.Mappings),
+		entriesFromMap(cfg.CustomMappings),
+	}
+	priorities := map[string][]string{}
+	for _, f := range files {
+		if err := f.validate(cfg.Strict); err != nil {
+			return nil, err
+		}
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		fileRules, err := entriesFromRules(logger, files[i].Rules, &guards)
+		if err != nil {
+			return nil, fmt.Errorf("mapping file %s: %w", files[i].path, err)
+		}
+		tables = append(tables, fileRules, entriesFromMap(files[i].Mappings))
+	}
+	for _, f := range files {
+		for dst, srcs := range f.Priorities {
+			priorities[dst] = srcs
+		}
+	}
+	for dst, srcs := range cfg.Priorities {
+		priorities[dst] = srcs
+	}
+	tables = append(tables, profileEntries(profiles))
+
+	if err := validateTargets(cfg.Targets); err != nil {
+		return nil, err
+	}
+	targets := cfg.Targets
+	if len(targets) == 0 {
+		targets = defaultTargets
+	}
+	if err := resolveChains(logger, tables, targets); err != nil {
+		return nil, err
+	}
+	sets, err := compileTargets(targets, priorities, tables...)
+	if err != nil {
+		return nil, err
 	}
 
 	m := map[string]string{}
-	if cfg.EnableDefaults {
-		for k, v := range defaultMappings {
-			m[k] = v
+	for i := len(tables) - 1; i >= 0; i-- {
+		for _, e := range tables[i] {
+			m[e.src] = e.dst
+		}
+	}
+
+	metricMappings := profileMappings(profiles, func(pr profile) map[string]string { return pr.metricMappings })
+	for _, f := range files {
+		for k, v := range f.MetricMappings {
+			metricMappings[k] = v
 		}
 	}
-	for k, v := range cfg.Mappings {
-		m[k] = v
+	for k, v := range cfg.CustomMetricMappings {
+		metricMappings[k] = v
+	}
+	for k, v := range cfg.MetricMappings {
+		metricMappings[k] = v
 	}
 
-	return &tracesProcessor{
-		logger:       settings.Logger,
-		next:         next,
-		overwrite:    cfg.Overwrite,
-		dropOriginal: cfg.DropOriginal,
-		mappings:     m,
+	var systems []systemPrefix
+	for _, pr := range profiles {
+		systems = append(systems, pr.systems...)
+	}
+
+	mig, err := newMigrator(cfg.TargetSemconvVersion, cfg.DualEmit)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelRules []modelRule
+	var modelFamilyAttr string
+	if cfg.ModelFamily.Enabled || cfg.Cost.PriceFile != "" {
+		if modelRules, err = compileModelRules(cfg.ModelFamily); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ModelFamily.Enabled {
+		modelFamilyAttr = cfg.ModelFamily.Attribute
+		if modelFamilyAttr == "" {
+			modelFamilyAttr = defaultModelFamilyAttr
+		}
+	}
+
+	return &normalizer{
+		logger:          logger,
+		overwrite:       cfg.Overwrite,
+		dropOriginal:    cfg.DropOriginal,
+		conflictPolicy:  cfg.ConflictPolicy,
+		mappings:        m,
+		metricMappings:  metricMappings,
+		sets:            sets,
+		hoist:           cfg.Hoist,
+		profiles:        profiles,
+		guards:          guards,
+		systems:         systems,
+		messageOutput:   cfg.MessageOutput,
+		deriveUsage:     cfg.DeriveUsage,
+		batchAttr:       cfg.Cost.BatchAttribute,
+		modelRules:      modelRules,
+		modelFamilyAttr: modelFamilyAttr,
+		semconvTypes:    cfg.SemconvTypes,
+		unknownAttrs:    unknownAttrs,
+		migrator:        mig,
 	}, nil
 }
 
-func (p *tracesProcessor) Capabilities() consumer.Capabilities {
-	return consumer.Capabilities{MutatesData: true}
+// uniqueStrings returns s without repeated values, keeping the first.
+func uniqueStrings(s []string) []string {
+	seen := map[string]bool{}
+	out := s[:0]
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// reportUnknown records a write to a gen_ai.* key the registry does not know.
+// Numeric key segments are collapsed to bound the cardinality of the metric.
+func (n *normalizer) reportUnknown(key string) {
+	n.unknownAttrs.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("key", unknownKeyLabel(key)), attribute.String("reason", "unknown_key")))
+	n.logger.Debug("wrote unknown gen_ai attribute", zap.String("key", key))
+}
+
+// reportUnknownValue records a write of a value outside the enum members of
+// a known gen_ai.* key. The value itself is only logged.
+func (n *normalizer) reportUnknownValue(key, value string) {
+	n.unknownAttrs.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("key", key), attribute.String("reason", "unknown_value")))
+	n.logger.Debug("wrote unknown gen_ai enum value", zap.String("key", key), zap.String("value", value))
+}
+
+// normalizeAttributes applies the mapping table for target and the semconv
+// migration to attrs, found in context rc.
+func (n *normalizer) normalizeAttributes(attrs pcommon.Map, target string, rc recordContext) {
+	n.applyMappings(attrs, target, n.evalGuards(attrs, rc))
+	n.migrator.migrate(attrs, n.overwrite)
+}
+
+// normalizeRecord normalizes the attributes of a span, log record or data
+// point and infers gen_ai.system when it is missing. Span and log record
+// attributes are first prepared by the enabled profiles. It returns the guard
+// results, for the profile transforms of the record.
+func (n *normalizer) normalizeRecord(attrs pcommon.Map, target string, rc recordContext) []bool {
+	active := n.evalGuards(attrs, rc)
+	if target == TargetSpan || target == TargetLog {
+		n.prepareRecord(attrs, active)
+	}
+	n.applyMappings(attrs, target, active)
+
+	_, exists := attrs.Get("gen_ai.system")
+	if !exists && n.migrator.usesProviderName() {
+		_, exists = attrs.Get("gen_ai.provider.name")
+	}
+	if !exists {
+		if system := n.inferSystem(attrs); system != "" {
+			attrs.PutStr("gen_ai.system", system)
+		}
+	}
+	if n.modelFamilyAttr != "" {
+		n.putModelFamily(attrs)
+	}
+	if target == TargetSpan || target == TargetLog {
+		if n.deriveUsage {
+			reconcileUsage(attrs)
+		}
+		n.estimateCost(attrs, recordTime(rc.time))
+	}
+
+	n.migrator.migrate(attrs, n.overwrite)
+	return active
+}
+
+// schemaURL returns the schema URL to stamp on the resources and scopes
+// carrying GenAI attributes, or "".
+func (n *normalizer) schemaURL() string {
+	if n.migrator == nil {
+		return ""
+	}
+	return n.migrator.schemaURL
+}
+
+type tracesProcessor struct {
+	*liveNormalizer
+	next consumer.Traces
 }
 
-func (p *tracesProcessor) Start(context.Context, component.Host) error { return nil }
-func (p *tracesProcessor) Shutdown(context.Context) error              { return nil }
+func newTracesProcessor(_ context.Context, settings processor.CreateSettings, cfg *Config, next consumer.Traces) (*tracesProcessor, error) {
+	if next == nil {
+		return nil, fmt.Errorf("next consumer is nil")
+	}
+
+	l, err := newLiveNormalizer(settings, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracesProcessor{
+		liveNormalizer: l,
+		next:           next,
+	}, nil
+}
 
 func (p *tracesProcessor) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	n := p.current.Load()
+	schemaURL := n.schemaURL()
 	rs := td.ResourceSpans()
 	for i := 0; i < rs.Len(); i++ {
+		res := rs.At(i).Resource()
+		resource := res.Attributes()
+		n.normalizeAttributes(resource, TargetResource, resourceContext(res))
+		up := n.newUpHoister()
+		resourceGenAI := hasGenAIAttributes(resource)
+
 		ss := rs.At(i).ScopeSpans()
 		for j := 0; j < ss.Len(); j++ {
+			rc := recordContext{resource: res, scope: ss.At(j).Scope(), scopeSpans: ss.At(j), resourceSpans: rs.At(i)}
+			n.normalizeAttributes(rc.scope.Attributes(), TargetScope, rc)
+			scopeGenAI := hasGenAIAttributes(rc.scope.Attributes())
+
 			spans := ss.At(j).Spans()
 			for k := 0; k < spans.Len(); k++ {
 				span := spans.At(k)
-				p.applyMappings(span.Attributes())
+				src := rc.withSpan(span)
+				active := n.normalizeRecord(span.Attributes(), TargetSpan, src)
+				n.transformSpan(span, active)
 
 				events := span.Events()
 				for e := 0; e < events.Len(); e++ {
-					p.applyMappings(events.At(e).Attributes())
+					n.normalizeAttributes(events.At(e).Attributes(), TargetEvent, src)
+				}
+
+				links := span.Links()
+				for l := 0; l < links.Len(); l++ {
+					n.normalizeAttributes(links.At(l).Attributes(), TargetLink, src)
 				}
+
+				n.hoistDown(resource, span.Attributes())
+				up.observe(span.Attributes())
+				scopeGenAI = scopeGenAI || hasGenAIAttributes(span.Attributes())
+			}
+			if scopeGenAI && schemaURL != "" {
+				ss.At(j).SetSchemaUrl(schemaURL)
+				resourceGenAI = true
 			}
 		}
+		up.apply(resource)
+		if resourceGenAI && schemaURL != "" {
+			rs.At(i).SetSchemaUrl(schemaURL)
+		}
 	}
 
 	return p.next.ConsumeTraces(ctx, td)
 }
 
-func (p *tracesProcessor) applyMappings(attrs pcommon.Map) {
-	// Iterate over mappings instead of attributes to avoid iterator invalidation
-	// when deleting keys.
-	for src, dst := range p.mappings {
-		val, ok := attrs.Get(src)
-		if !ok {
-			continue
-		}
-
-		if src == dst {
-			continue
-		}
-
-		if _, exists := attrs.Get(dst); exists && !p.overwrite {
-			// Destination already present and overwrite disabled.
-			continue
-		}
-
-		// Copy value.
-		val.CopyTo(attrs.PutEmpty(dst))
-
-		// Optionally drop original.
-		if p.dropOriginal {
-			attrs.Remove(src)
+// inferSystem returns the system of the first key matching a prefix of the
+// enabled profiles.
+func (n *normalizer) inferSystem(attrs pcommon.Map) string {
+	found := ""
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		for _, sp := range n.systems {
+			if len(k) > len(sp.prefix) && k[:len(sp.prefix)] == sp.prefix {
+				found = sp.system
+				return false
+			}
 		}
-	}
+		return true
+	})
+	return found
 }
@@ -7,6 +7,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor"
 	"go.uber.org/zap"
@@ -52,6 +54,29 @@ func TestApplyMappings_SpanAndEvents(t *testing.T) {
 	assertHasStr(t, eattrs, "gen_ai.request.model", "gpt-4.1")
 }
 
+func TestApplyMappings_SpanLinks(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := &Config{
+		Mappings: map[string]string{"llm.provider": "gen_ai.provider.name"},
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	link := sp.Links().AppendEmpty()
+	link.Attributes().PutStr("llm.provider", "openai")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, link.Attributes(), "gen_ai.provider.name", "openai")
+}
+
 func TestOverwriteAndDropOriginal(t *testing.T) {
 	next := consumertest.NewNop()
 	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
@@ -85,6 +110,177 @@ func TestOverwriteAndDropOriginal(t *testing.T) {
 	}
 }
 
+func TestApplyMappings_Logs(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := &Config{
+		EnableDefaults: false,
+		Mappings: map[string]string{
+			"llm.model_name": "gen_ai.request.model",
+			"llm.provider":   "gen_ai.provider.name",
+			"llm.prompt":     "gen_ai.prompt",
+		},
+	}
+
+	p, err := newLogsProcessor(context.Background(), settings, cfg, sink)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("llm.provider", "openai")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutStr("event.name", "llm.prompt")
+	lr.Attributes().PutStr("llm.model_name", "gpt-4.1")
+
+	if err := p.ConsumeLogs(context.Background(), ld); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, rl.Resource().Attributes(), "gen_ai.provider.name", "openai")
+	assertHasStr(t, lr.Attributes(), "gen_ai.request.model", "gpt-4.1")
+	assertHasStr(t, lr.Attributes(), "event.name", "gen_ai.prompt")
+}
+
+func TestApplyMappings_Metrics(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := &Config{
+		EnableDefaults: true,
+		Mappings: map[string]string{
+			"vendor.model": "gen_ai.request.model",
+		},
+		MetricMappings: map[string]string{
+			"vendor.latency": "gen_ai.client.operation.duration",
+		},
+	}
+
+	p, err := newMetricsProcessor(context.Background(), settings, cfg, sink)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	tokens := metrics.AppendEmpty()
+	tokens.SetName("llm.token.count")
+	tokensDP := tokens.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	tokensDP.Attributes().PutStr("llm.model", "gpt-4.1")
+
+	latency := metrics.AppendEmpty()
+	latency.SetName("vendor.latency")
+	latencyDP := latency.SetEmptySummary().DataPoints().AppendEmpty()
+	latencyDP.Attributes().PutStr("vendor.model", "gpt-4.1")
+
+	if err := p.ConsumeMetrics(context.Background(), md); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	if tokens.Name() != "gen_ai.client.token.usage" {
+		t.Fatalf("unexpected metric name %q", tokens.Name())
+	}
+	assertHasStr(t, tokensDP.Attributes(), "gen_ai.request.model", "gpt-4.1")
+
+	if latency.Name() != "gen_ai.client.operation.duration" {
+		t.Fatalf("unexpected metric name %q", latency.Name())
+	}
+	assertHasStr(t, latencyDP.Attributes(), "gen_ai.request.model", "gpt-4.1")
+}
+
+func TestDefaults_VendorKeysAndSystemInference(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, sink)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	oai := spans.AppendEmpty()
+	oai.Attributes().PutStr("openai.model", "gpt-4o")
+	oai.Attributes().PutInt("openai.prompt_tokens", 100)
+	ant := spans.AppendEmpty()
+	ant.Attributes().PutStr("anthropic.model", "claude-3-opus")
+	ant.Attributes().PutInt("anthropic.input_tokens", 200)
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, oai.Attributes(), "gen_ai.request.model", "gpt-4o")
+	assertHasStr(t, oai.Attributes(), "gen_ai.system", "openai")
+	if v, ok := oai.Attributes().Get("gen_ai.usage.input_tokens"); !ok || v.Int() != 100 {
+		t.Fatalf("expected gen_ai.usage.input_tokens=100, got %v", v)
+	}
+
+	assertHasStr(t, ant.Attributes(), "gen_ai.request.model", "claude-3-opus")
+	assertHasStr(t, ant.Attributes(), "gen_ai.system", "anthropic")
+	if v, ok := ant.Attributes().Get("gen_ai.usage.input_tokens"); !ok || v.Int() != 200 {
+		t.Fatalf("expected gen_ai.usage.input_tokens=200, got %v", v)
+	}
+}
+
+func TestNoOverwrite(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, sink)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("gen_ai.request.model", "existing-model")
+	sp.Attributes().PutStr("openai.model", "gpt-4o")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, sp.Attributes(), "gen_ai.request.model", "existing-model")
+}
+
+func TestDeprecatedCustomMappings(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.CustomMappings = map[string]string{
+		"my_custom.model_name": "gen_ai.request.model",
+		"my_custom.provider":   "gen_ai.system",
+	}
+	cfg.Mappings = map[string]string{
+		"my_custom.provider": "gen_ai.provider.name",
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, sink)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("my_custom.model_name", "custom-model-v2")
+	sp.Attributes().PutStr("my_custom.provider", "acme")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, sp.Attributes(), "gen_ai.request.model", "custom-model-v2")
+	// mappings wins over custom_mappings for the same source key.
+	assertHasStr(t, sp.Attributes(), "gen_ai.provider.name", "acme")
+	if _, ok := sp.Attributes().Get("gen_ai.system"); ok {
+		t.Fatalf("expected custom_mappings entry to be overridden by mappings")
+	}
+}
+
 func assertHasStr(t *testing.T, m pcommon.Map, key, want string) {
 	v, ok := m.Get(key)
 	if !ok {
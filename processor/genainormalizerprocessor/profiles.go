@@ -0,0 +1,319 @@
+package genainormalizerprocessor
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Built-in profile names.
+const (
+	ProfileLLM           = "llm"
+	ProfileOpenAI        = "openai"
+	ProfileAnthropic     = "anthropic"
+	ProfileCohere        = "cohere"
+	ProfileAzure         = "azure"
+	ProfileGoogle        = "google"
+	ProfileOpenInference = "openinference"
+	ProfileTraceloop     = "traceloop"
+	ProfileBedrock       = "bedrock"
+	ProfileLangChain     = "langchain"
+	ProfileVLLM          = "vllm"
+	ProfileOllama        = "ollama"
+	ProfileTGI           = "tgi"
+	ProfileLlamaCpp      = "llamacpp"
+)
+
+// defaultProfiles are enabled by enable_defaults when no profiles are listed.
+var defaultProfiles = []string{
+	ProfileLLM, ProfileOpenAI, ProfileAnthropic, ProfileCohere, ProfileAzure, ProfileGoogle,
+}
+
+// profile is a named, opt-in bundle of normalization behavior for one
+// instrumentation family.
+type profile struct {
+	name string
+	// mappings are added to the mapping table after the user-provided
+	// mappings.
+	mappings map[string]string
+	// metricMappings rename metrics, after the user-provided metric mappings.
+	metricMappings map[string]string
+	// systems infer gen_ai.system from key prefixes.
+	systems []systemPrefix
+	// guard, if set, restricts the mappings and the prepare step of the
+	// profile to the attributes it accepts.
+	guard func(attrs pcommon.Map) bool
+	// condition, if set, restricts the profile like guard; it comes from
+	// profile_conditions.
+	condition *condition
+	// slot is the index of the combined guard and condition in the
+	// normalizer guards.
+	slot int
+	// prepare rewrites span and log record attributes before they are
+	// mapped, e.g. to extract values from JSON encoded payloads.
+	prepare func(n *normalizer, attrs pcommon.Map)
+	// span rewrites a span after its attributes have been mapped.
+	span func(n *normalizer, span ptrace.Span)
+	// log rewrites a log record after its attributes have been mapped. New
+	// records are appended to out.
+	log func(n *normalizer, record plog.LogRecord, out plog.LogRecordSlice)
+}
+
+var builtinProfiles = map[string]profile{
+	ProfileLLM: {
+		mappings:       llmMappings,
+		metricMappings: llmMetricMappings,
+	},
+	ProfileOpenAI: {
+		mappings:       openAIMappings,
+		metricMappings: openAIMetricMappings,
+		systems:        []systemPrefix{{"openai.", "openai"}},
+	},
+	ProfileAnthropic: {
+		mappings:       anthropicMappings,
+		metricMappings: anthropicMetricMappings,
+		systems:        []systemPrefix{{"anthropic.", "anthropic"}},
+	},
+	ProfileCohere: {
+		mappings: cohereMappings,
+		systems:  []systemPrefix{{"cohere.", "cohere"}},
+	},
+	ProfileAzure: {
+		mappings: azureMappings,
+		systems:  []systemPrefix{{"az.ai.", "az.ai.openai"}},
+	},
+	ProfileGoogle: {
+		mappings: googleMappings,
+		systems:  []systemPrefix{{"google.", "vertex_ai"}},
+	},
+	ProfileBedrock: {
+		mappings: bedrockMappings,
+		prepare:  (*normalizer).normalizeBedrock,
+	},
+	ProfileLangChain: {
+		mappings: langchainMappings,
+		guard:    isLangChainGenAIRun,
+		prepare:  (*normalizer).normalizeLangChain,
+	},
+	ProfileVLLM:     vllmServer.profile(),
+	ProfileOllama:   ollamaServer.profile(),
+	ProfileTGI:      tgiServer.profile(),
+	ProfileLlamaCpp: llamaCppServer.profile(),
+	ProfileOpenInference: func() profile {
+		pr := messageProfile(openInferenceMessages)
+		pr.mappings = openInferenceMappings
+		return pr
+	}(),
+	ProfileTraceloop: func() profile {
+		pr := messageProfile(traceloopMessages)
+		pr.mappings = traceloopMappings
+		return pr
+	}(),
+}
+
+// messageProfile returns a profile recording the messages extracted from span
+// and log record attributes according to message_output.
+func messageProfile(extract func(pcommon.Map) (inputs, outputs []genaiMessage, keys []string)) profile {
+	return profile{
+		span: func(n *normalizer, span ptrace.Span) {
+			inputs, outputs, keys := extract(span.Attributes())
+			if len(keys) == 0 {
+				return
+			}
+			n.dropKeys(span.Attributes(), keys)
+			if n.messageEvents() {
+				appendMessageSpanEvents(span, inputs, outputs)
+			}
+			n.putMessages(span.Attributes(), inputs, outputs)
+		},
+		log: func(n *normalizer, record plog.LogRecord, out plog.LogRecordSlice) {
+			inputs, outputs, keys := extract(record.Attributes())
+			if len(keys) == 0 {
+				return
+			}
+			n.dropKeys(record.Attributes(), keys)
+			if n.messageEvents() {
+				appendMessageLogRecords(record, out, inputs, outputs)
+			}
+			n.putMessages(record.Attributes(), inputs, outputs)
+		},
+	}
+}
+
+// messageEvents reports whether messages are recorded as events.
+func (n *normalizer) messageEvents() bool {
+	return n.messageOutput != MessageOutputAttributes
+}
+
+// putMessages writes the finish reasons of outputs and, when message_output
+// asks for it, the message list attributes.
+func (n *normalizer) putMessages(attrs pcommon.Map, inputs, outputs []genaiMessage) {
+	putFinishReasons(attrs, outputs, n.overwrite)
+	if n.messageOutput == MessageOutputAttributes || n.messageOutput == MessageOutputBoth {
+		putMessageAttributes(attrs, inputs, outputs, n.overwrite)
+	}
+}
+
+// resolveProfiles returns the named profiles, in order. Without names,
+// enableDefaults selects the default profiles.
+func resolveProfiles(names []string, enableDefaults bool) ([]profile, error) {
+	if len(names) == 0 && enableDefaults {
+		names = defaultProfiles
+	}
+	profiles := make([]profile, 0, len(names))
+	for _, name := range names {
+		pr, ok := builtinProfiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(sortedKeys(builtinProfiles), ", "))
+		}
+		pr.name = name
+		profiles = append(profiles, pr)
+	}
+	return profiles, nil
+}
+
+// enabledProfiles returns the inline profiles, or the default ones when none
+// are listed and enable_defaults is set, followed by the profiles listed in
+// files.
+func enabledProfiles(cfg *Config, files []mappingFile) ([]profile, error) {
+	names := cfg.Profiles
+	if len(names) == 0 && cfg.EnableDefaults {
+		names = defaultProfiles
+	}
+	names = append([]string(nil), names...)
+	for _, f := range files {
+		names = append(names, f.Profiles...)
+	}
+	return resolveProfiles(uniqueStrings(names), false)
+}
+
+// profileMappings merges the mappings of profiles into one table; earlier
+// profiles win for the same source key.
+func profileMappings(profiles []profile, table func(profile) map[string]string) map[string]string {
+	m := map[string]string{}
+	for i := len(profiles) - 1; i >= 0; i-- {
+		for k, v := range table(profiles[i]) {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// guarded reports whether the profile applies to some records only.
+func (pr profile) guarded() bool {
+	return pr.guard != nil || pr.condition != nil
+}
+
+// profileGuards assigns a guard slot to every guarded profile and returns the
+// guards by slot.
+func profileGuards(profiles []profile) []guard {
+	var guards []guard
+	for i := range profiles {
+		pr := profiles[i]
+		if !pr.guarded() {
+			continue
+		}
+		profiles[i].slot = len(guards)
+		guards = append(guards, func(attrs pcommon.Map, rc recordContext) bool {
+			return (pr.guard == nil || pr.guard(attrs)) && (pr.condition == nil || pr.condition.match(attrs, rc))
+		})
+	}
+	return guards
+}
+
+// profileEntries returns the attribute mappings of profiles as one table,
+// sorted by source key; earlier profiles win for the same source key. An
+// entry applies where any of the profiles mapping its source key applies.
+func profileEntries(profiles []profile) []mappingEntry {
+	bySrc := map[string]mappingEntry{}
+	unguarded := map[string]bool{}
+	for i := len(profiles) - 1; i >= 0; i-- {
+		pr := profiles[i]
+		for src, dst := range pr.mappings {
+			e := mappingEntry{src: src, dst: dst, guards: bySrc[src].guards}
+			if !pr.guarded() {
+				unguarded[src] = true
+			} else {
+				e.guards = append(e.guards, pr.slot)
+			}
+			bySrc[src] = e
+		}
+	}
+	entries := make([]mappingEntry, 0, len(bySrc))
+	for _, src := range sortedKeys(bySrc) {
+		e := bySrc[src]
+		if unguarded[src] {
+			e.guards = nil
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// evalGuards returns the results of the guards for attrs in context rc.
+func (n *normalizer) evalGuards(attrs pcommon.Map, rc recordContext) []bool {
+	if len(n.guards) == 0 {
+		return nil
+	}
+	active := make([]bool, len(n.guards))
+	for i, g := range n.guards {
+		active[i] = g(attrs, rc)
+	}
+	return active
+}
+
+func (n *normalizer) profileNames() []string {
+	names := make([]string, 0, len(n.profiles))
+	for _, pr := range n.profiles {
+		names = append(names, pr.name)
+	}
+	return names
+}
+
+// prepareRecord runs the attribute preparation of the enabled profiles
+// whose guard accepts the record.
+func (n *normalizer) prepareRecord(attrs pcommon.Map, active []bool) {
+	for _, pr := range n.profiles {
+		if pr.prepare != nil && pr.applies(active) {
+			pr.prepare(n, attrs)
+		}
+	}
+}
+
+// applies reports whether pr applies given the guard results of a record.
+func (pr profile) applies(active []bool) bool {
+	return !pr.guarded() || active[pr.slot]
+}
+
+// transformSpan runs the span transforms of the enabled profiles that apply
+// to the span.
+func (n *normalizer) transformSpan(span ptrace.Span, active []bool) {
+	for _, pr := range n.profiles {
+		if pr.span != nil && pr.applies(active) {
+			pr.span(n, span)
+		}
+	}
+}
+
+// transformLog runs the log transforms of the enabled profiles that apply to
+// the record.
+func (n *normalizer) transformLog(record plog.LogRecord, out plog.LogRecordSlice, active []bool) {
+	for _, pr := range n.profiles {
+		if pr.log != nil && pr.applies(active) {
+			pr.log(n, record, out)
+		}
+	}
+}
+
+// dropKeys removes keys a profile consumed when drop_original is set.
+func (n *normalizer) dropKeys(attrs pcommon.Map, keys []string) {
+	if !n.dropOriginal {
+		return
+	}
+	for _, k := range keys {
+		attrs.Remove(k)
+	}
+}
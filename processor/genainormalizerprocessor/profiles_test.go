@@ -0,0 +1,126 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestSelectedProfilesOnly(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileOpenAI}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	openai := spans.AppendEmpty()
+	openai.Attributes().PutStr("openai.model", "gpt-4o")
+	google := spans.AppendEmpty()
+	google.Attributes().PutStr("google.model", "storage-v1")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, openai.Attributes(), "gen_ai.request.model", "gpt-4o")
+	assertHasStr(t, openai.Attributes(), "gen_ai.system", "openai")
+	for _, k := range []string{"gen_ai.request.model", "gen_ai.system"} {
+		if _, ok := google.Attributes().Get(k); ok {
+			t.Fatalf("expected %s to be unset without the google profile", k)
+		}
+	}
+}
+
+func TestProfileMetricMappings(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileAnthropic}
+
+	p, err := newMetricsProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	anthropic := metrics.AppendEmpty()
+	anthropic.SetName("anthropic.tokens.used")
+	openai := metrics.AppendEmpty()
+	openai.SetName("openai.tokens.used")
+
+	if err := p.ConsumeMetrics(context.Background(), md); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	if anthropic.Name() != "gen_ai.client.token.usage" {
+		t.Fatalf("expected anthropic metric to be renamed, got %q", anthropic.Name())
+	}
+	if openai.Name() != "openai.tokens.used" {
+		t.Fatalf("expected openai metric to be untouched, got %q", openai.Name())
+	}
+}
+
+func TestNoDefaultProfiles(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("anthropic.model", "claude-3-opus")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	if sp.Attributes().Len() != 1 {
+		t.Fatalf("expected no normalization without profiles, got %v", sp.Attributes().AsRaw())
+	}
+}
+
+func TestGuardedProfileSharesMappings(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileVLLM, ProfileTraceloop}
+
+	// gen_ai.usage.prompt_tokens is mapped by the guarded vllm profile and
+	// the unguarded traceloop profile, so it applies outside vLLM spans too.
+	attrs := consumeSpan(t, cfg, map[string]any{"gen_ai.usage.prompt_tokens": 7})
+	if v, ok := attrs.Get("gen_ai.usage.input_tokens"); !ok || v.Int() != 7 {
+		t.Fatalf("expected gen_ai.usage.input_tokens=7, got %v", attrs.AsRaw())
+	}
+	if _, ok := attrs.Get("gen_ai.provider.name"); ok {
+		t.Fatalf("expected the vllm profile not to apply, got %v", attrs.AsRaw())
+	}
+}
+
+func TestOpenAIAPIBaseIsNotASystem(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileOpenAI}
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"openai.model":    "gpt-4o",
+		"openai.api_base": "https://api.openai.com/v1",
+	})
+	assertHasStr(t, attrs, "gen_ai.system", "openai")
+
+	cfg.TargetSemconvVersion = "1.37.0"
+	attrs = consumeSpan(t, cfg, map[string]any{
+		"openai.model":    "gpt-4o",
+		"openai.api_base": "https://api.openai.com/v1",
+	})
+	assertHasStr(t, attrs, "gen_ai.provider.name", "openai")
+}
@@ -0,0 +1,272 @@
+package genainormalizerprocessor
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Destination keys of the server timings.
+const (
+	serverDurationAttr         = "gen_ai.server.request.duration"
+	serverTimeToFirstTokenAttr = "gen_ai.server.time_to_first_token"
+	serverTimePerOutputAttr    = "gen_ai.server.time_per_output_token"
+)
+
+// Unit scales converting timing fields to seconds.
+const (
+	seconds      = 1.0
+	milliseconds = 1e-3
+	nanoseconds  = 1e-9
+)
+
+// selfHostedServer describes the attributes of one self-hosted inference
+// server.
+type selfHostedServer struct {
+	// prefixes identify the attributes of the server.
+	prefixes []string
+	provider string
+	// urlKeys hold the server URL or host:port, in lookup order.
+	urlKeys []string
+	// defaultPort is used when the URL has neither a scheme nor a port.
+	defaultPort int64
+	mappings    map[string]string
+	timings     []timingRule
+}
+
+// timingRule derives a timing in seconds from the sum of the source fields,
+// optionally divided by a count field.
+type timingRule struct {
+	dst   string
+	sum   []string
+	per   string
+	scale float64
+}
+
+var vllmServer = selfHostedServer{
+	// vLLM's own tracing writes gen_ai.latency.* and a few pre-standard
+	// gen_ai.* keys.
+	prefixes:    []string{"vllm.", "gen_ai.latency."},
+	provider:    "vllm",
+	urlKeys:     []string{"vllm.url", "vllm.api_base"},
+	defaultPort: 8000,
+	mappings: map[string]string{
+		"vllm.model":                     "gen_ai.request.model",
+		"vllm.request.temperature":       "gen_ai.request.temperature",
+		"vllm.request.top_p":             "gen_ai.request.top_p",
+		"vllm.request.top_k":             "gen_ai.request.top_k",
+		"vllm.request.max_tokens":        "gen_ai.request.max_tokens",
+		"vllm.request.n":                 "gen_ai.request.choice.count",
+		"vllm.request.seed":              "gen_ai.request.seed",
+		"vllm.usage.prompt_tokens":       "gen_ai.usage.input_tokens",
+		"vllm.usage.completion_tokens":   "gen_ai.usage.output_tokens",
+		"vllm.usage.total_tokens":        "gen_ai.usage.total_tokens",
+		"vllm.response.finish_reason":    "gen_ai.response.finish_reasons",
+		"gen_ai.request.n":               "gen_ai.request.choice.count",
+		"gen_ai.request.id":              "gen_ai.response.id",
+		"gen_ai.usage.prompt_tokens":     "gen_ai.usage.input_tokens",
+		"gen_ai.usage.completion_tokens": "gen_ai.usage.output_tokens",
+	},
+	timings: []timingRule{
+		{dst: serverTimeToFirstTokenAttr, sum: []string{"vllm.latency.time_to_first_token"}, scale: seconds},
+		{dst: serverTimeToFirstTokenAttr, sum: []string{"gen_ai.latency.time_to_first_token"}, scale: seconds},
+		{dst: serverDurationAttr, sum: []string{"vllm.latency.e2e"}, scale: seconds},
+		{dst: serverDurationAttr, sum: []string{"gen_ai.latency.e2e"}, scale: seconds},
+	},
+}
+
+// ollamaServer covers the fields of Ollama's /api/generate and /api/chat
+// responses, whose durations are in nanoseconds.
+var ollamaServer = selfHostedServer{
+	prefixes:    []string{"ollama."},
+	provider:    "ollama",
+	urlKeys:     []string{"ollama.host", "ollama.url"},
+	defaultPort: 11434,
+	mappings: map[string]string{
+		"ollama.model":               "gen_ai.request.model",
+		"ollama.options.temperature": "gen_ai.request.temperature",
+		"ollama.options.top_p":       "gen_ai.request.top_p",
+		"ollama.options.top_k":       "gen_ai.request.top_k",
+		"ollama.options.num_predict": "gen_ai.request.max_tokens",
+		"ollama.options.seed":        "gen_ai.request.seed",
+		"ollama.options.stop":        "gen_ai.request.stop_sequences",
+		"ollama.prompt_eval_count":   "gen_ai.usage.input_tokens",
+		"ollama.eval_count":          "gen_ai.usage.output_tokens",
+		"ollama.done_reason":         "gen_ai.response.finish_reasons",
+	},
+	timings: []timingRule{
+		{dst: serverDurationAttr, sum: []string{"ollama.total_duration"}, scale: nanoseconds},
+		{dst: serverTimeToFirstTokenAttr, sum: []string{"ollama.load_duration", "ollama.prompt_eval_duration"}, scale: nanoseconds},
+		{dst: serverTimePerOutputAttr, sum: []string{"ollama.eval_duration"}, per: "ollama.eval_count", scale: nanoseconds},
+	},
+}
+
+// tgiServer covers HuggingFace Text Generation Inference requests and the
+// x-*-time response headers, which are in milliseconds.
+var tgiServer = selfHostedServer{
+	prefixes:    []string{"tgi."},
+	provider:    "tgi",
+	urlKeys:     []string{"tgi.url", "tgi.endpoint"},
+	defaultPort: 8080,
+	mappings: map[string]string{
+		"tgi.model_id":                  "gen_ai.request.model",
+		"tgi.parameters.temperature":    "gen_ai.request.temperature",
+		"tgi.parameters.top_p":          "gen_ai.request.top_p",
+		"tgi.parameters.top_k":          "gen_ai.request.top_k",
+		"tgi.parameters.max_new_tokens": "gen_ai.request.max_tokens",
+		"tgi.parameters.seed":           "gen_ai.request.seed",
+		"tgi.parameters.stop":           "gen_ai.request.stop_sequences",
+		"tgi.prompt_tokens":             "gen_ai.usage.input_tokens",
+		"tgi.generated_tokens":          "gen_ai.usage.output_tokens",
+		"tgi.finish_reason":             "gen_ai.response.finish_reasons",
+	},
+	timings: []timingRule{
+		{dst: serverDurationAttr, sum: []string{"tgi.total_time"}, scale: milliseconds},
+		{dst: serverTimePerOutputAttr, sum: []string{"tgi.time_per_token"}, scale: milliseconds},
+	},
+}
+
+// llamaCppServer covers the llama.cpp server /completion endpoint, whose
+// timings are in milliseconds.
+var llamaCppServer = selfHostedServer{
+	prefixes:    []string{"llamacpp."},
+	provider:    "llama.cpp",
+	urlKeys:     []string{"llamacpp.url", "llamacpp.endpoint"},
+	defaultPort: 8080,
+	mappings: map[string]string{
+		"llamacpp.model":            "gen_ai.request.model",
+		"llamacpp.temperature":      "gen_ai.request.temperature",
+		"llamacpp.top_p":            "gen_ai.request.top_p",
+		"llamacpp.top_k":            "gen_ai.request.top_k",
+		"llamacpp.n_predict":        "gen_ai.request.max_tokens",
+		"llamacpp.seed":             "gen_ai.request.seed",
+		"llamacpp.stop":             "gen_ai.request.stop_sequences",
+		"llamacpp.tokens_evaluated": "gen_ai.usage.input_tokens",
+		"llamacpp.tokens_predicted": "gen_ai.usage.output_tokens",
+	},
+	timings: []timingRule{
+		{dst: serverTimeToFirstTokenAttr, sum: []string{"llamacpp.timings.prompt_ms"}, scale: milliseconds},
+		{dst: serverTimePerOutputAttr, sum: []string{"llamacpp.timings.predicted_per_token_ms"}, scale: milliseconds},
+		{dst: serverDurationAttr, sum: []string{"llamacpp.timings.prompt_ms", "llamacpp.timings.predicted_ms"}, scale: milliseconds},
+	},
+}
+
+// profile returns the profile of the server.
+func (s selfHostedServer) profile() profile {
+	return profile{
+		mappings: s.mappings,
+		guard:    s.matches,
+		prepare:  s.normalize,
+	}
+}
+
+// matches reports whether attrs carry a key of the server.
+func (s selfHostedServer) matches(attrs pcommon.Map) bool {
+	found := false
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		for _, p := range s.prefixes {
+			if strings.HasPrefix(k, p) {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// normalize fills gen_ai.provider.name, server.address and server.port and
+// converts the timing fields of attrs to seconds.
+func (s selfHostedServer) normalize(n *normalizer, attrs pcommon.Map) {
+	n.put(attrs, "gen_ai.provider.name", pcommon.NewValueStr(s.provider))
+
+	var consumed []string
+	for _, k := range s.urlKeys {
+		v, ok := attrs.Get(k)
+		if !ok {
+			continue
+		}
+		if host, port, ok := parseServerURL(v.AsString(), s.defaultPort); ok {
+			n.put(attrs, "server.address", pcommon.NewValueStr(host))
+			n.put(attrs, "server.port", pcommon.NewValueInt(port))
+			consumed = append(consumed, k)
+		}
+		break
+	}
+
+	written := map[string]bool{}
+	for _, t := range s.timings {
+		if written[t.dst] {
+			continue
+		}
+		v, srcs, ok := t.derive(attrs)
+		if !ok {
+			continue
+		}
+		n.put(attrs, t.dst, pcommon.NewValueDouble(v))
+		written[t.dst] = true
+		consumed = append(consumed, srcs...)
+	}
+	n.dropKeys(attrs, consumed)
+}
+
+// derive returns the timing in seconds and the fields it was derived from.
+// Every field of sum, and per if set, must be present.
+func (t timingRule) derive(attrs pcommon.Map) (float64, []string, bool) {
+	total := 0.0
+	for _, k := range t.sum {
+		v, ok := attrs.Get(k)
+		if !ok {
+			return 0, nil, false
+		}
+		f, ok := asDouble(v)
+		if !ok {
+			return 0, nil, false
+		}
+		total += f
+	}
+	srcs := t.sum
+	if t.per != "" {
+		v, ok := attrs.Get(t.per)
+		if !ok {
+			return 0, nil, false
+		}
+		count, ok := asDouble(v)
+		if !ok || count <= 0 {
+			return 0, nil, false
+		}
+		total /= count
+	}
+	return total * t.scale, srcs, true
+}
+
+// parseServerURL returns the host and port of a URL or host:port. Without a
+// port, the scheme's default port is used, or defaultPort when there is no
+// scheme either.
+func parseServerURL(raw string, defaultPort int64) (string, int64, bool) {
+	raw = strings.TrimSpace(raw)
+	hasScheme := strings.Contains(raw, "://")
+	if !hasScheme {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return "", 0, false
+	}
+	if p := u.Port(); p != "" {
+		port, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return "", 0, false
+		}
+		return u.Hostname(), port, true
+	}
+	if !hasScheme {
+		return u.Hostname(), defaultPort, true
+	}
+	if u.Scheme == "https" {
+		return u.Hostname(), 443, true
+	}
+	return u.Hostname(), 80, true
+}
@@ -0,0 +1,152 @@
+package genainormalizerprocessor
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseServerURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		host string
+		port int64
+	}{
+		{"http://gpu-1:9000/v1", "gpu-1", 9000},
+		{"localhost", "localhost", 11434},
+		{"10.0.0.5:8081", "10.0.0.5", 8081},
+		{"https://tgi.internal", "tgi.internal", 443},
+		{"http://tgi.internal", "tgi.internal", 80},
+	}
+	for _, tt := range tests {
+		host, port, ok := parseServerURL(tt.raw, 11434)
+		if !ok || host != tt.host || port != tt.port {
+			t.Errorf("parseServerURL(%q) = %q, %d, %v; want %q, %d", tt.raw, host, port, ok, tt.host, tt.port)
+		}
+	}
+	if _, _, ok := parseServerURL("http://", 80); ok {
+		t.Errorf("expected URL without host to be rejected")
+	}
+}
+
+func TestSelfHostedProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		attrs   map[string]any
+		want    map[string]any
+	}{
+		{
+			name:    "ollama",
+			profile: ProfileOllama,
+			attrs: map[string]any{
+				"ollama.host":                 "localhost",
+				"ollama.model":                "llama3.1:8b",
+				"ollama.options.num_predict":  128,
+				"ollama.prompt_eval_count":    26,
+				"ollama.eval_count":           290,
+				"ollama.total_duration":       5043500667,
+				"ollama.load_duration":        5025959,
+				"ollama.prompt_eval_duration": 325953000,
+				"ollama.eval_duration":        4709213000,
+			},
+			want: map[string]any{
+				"gen_ai.provider.name":                "ollama",
+				"server.address":                      "localhost",
+				"server.port":                         int64(11434),
+				"gen_ai.request.model":                "llama3.1:8b",
+				"gen_ai.request.max_tokens":           int64(128),
+				"gen_ai.usage.input_tokens":           int64(26),
+				"gen_ai.usage.output_tokens":          int64(290),
+				"gen_ai.server.request.duration":      5.043500667,
+				"gen_ai.server.time_to_first_token":   0.330978959,
+				"gen_ai.server.time_per_output_token": 4.709213 / 290,
+			},
+		},
+		{
+			name:    "vllm",
+			profile: ProfileVLLM,
+			attrs: map[string]any{
+				"vllm.url":                           "http://gpu-1:9000/v1",
+				"gen_ai.response.model":              "meta-llama/Llama-3.1-8B-Instruct",
+				"gen_ai.request.id":                  "cmpl-1",
+				"gen_ai.usage.prompt_tokens":         12,
+				"gen_ai.latency.time_to_first_token": 0.05,
+				"gen_ai.latency.e2e":                 1.5,
+			},
+			want: map[string]any{
+				"gen_ai.provider.name":              "vllm",
+				"server.address":                    "gpu-1",
+				"server.port":                       int64(9000),
+				"gen_ai.response.model":             "meta-llama/Llama-3.1-8B-Instruct",
+				"gen_ai.response.id":                "cmpl-1",
+				"gen_ai.usage.input_tokens":         int64(12),
+				"gen_ai.server.time_to_first_token": 0.05,
+				"gen_ai.server.request.duration":    1.5,
+			},
+		},
+		{
+			name:    "tgi",
+			profile: ProfileTGI,
+			attrs: map[string]any{
+				"tgi.url":                       "https://tgi.internal",
+				"tgi.parameters.max_new_tokens": "64",
+				"tgi.total_time":                250,
+				"tgi.time_per_token":            20,
+			},
+			want: map[string]any{
+				"gen_ai.provider.name":                "tgi",
+				"server.address":                      "tgi.internal",
+				"server.port":                         int64(443),
+				"gen_ai.request.max_tokens":           int64(64),
+				"gen_ai.server.request.duration":      0.25,
+				"gen_ai.server.time_per_output_token": 0.02,
+			},
+		},
+		{
+			name:    "llama.cpp",
+			profile: ProfileLlamaCpp,
+			attrs: map[string]any{
+				"llamacpp.endpoint":                       "127.0.0.1",
+				"llamacpp.tokens_predicted":               16,
+				"llamacpp.timings.prompt_ms":              120.0,
+				"llamacpp.timings.predicted_ms":           380.0,
+				"llamacpp.timings.predicted_per_token_ms": 23.75,
+			},
+			want: map[string]any{
+				"gen_ai.provider.name":                "llama.cpp",
+				"server.address":                      "127.0.0.1",
+				"server.port":                         int64(8080),
+				"gen_ai.usage.output_tokens":          int64(16),
+				"gen_ai.server.time_to_first_token":   0.12,
+				"gen_ai.server.time_per_output_token": 0.02375,
+				"gen_ai.server.request.duration":      0.5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig()
+			cfg.Profiles = []string{tt.profile}
+			cfg.DropOriginal = true
+			attrs := consumeSpan(t, cfg, tt.attrs).AsRaw()
+
+			for k, want := range tt.want {
+				got := attrs[k]
+				if w, ok := want.(float64); ok {
+					if g, ok := got.(float64); !ok || math.Abs(g-w) > 1e-9 {
+						t.Errorf("%s = %#v, want %v", k, got, w)
+					}
+					continue
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %#v, want %#v", k, got, want)
+				}
+			}
+			if len(attrs) != len(tt.want) {
+				t.Errorf("expected only normalized attributes with drop_original, got %v", attrs)
+			}
+		})
+	}
+}
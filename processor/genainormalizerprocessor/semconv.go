@@ -0,0 +1,151 @@
+package genainormalizerprocessor
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Stability levels of semantic convention attributes.
+const (
+	stabilityDevelopment = "development"
+	stabilityDeprecated  = "deprecated"
+)
+
+// attributeDef describes one gen_ai.* attribute of the semantic conventions.
+type attributeDef struct {
+	// typ is the value type, using the same names as MappingRule.Type. Empty
+	// means any type is accepted.
+	typ string
+	// members lists the well-known enum values, if the attribute is an enum.
+	members []string
+	// stability is the stability level in the registry.
+	stability string
+}
+
+// semconvRegistry holds the known gen_ai.* attributes.
+// See: https://opentelemetry.io/docs/specs/semconv/registry/attributes/gen-ai/
+var semconvRegistry = map[string]attributeDef{
+	// Provider and operation.
+	"gen_ai.provider.name": {typ: TypeString, stability: stabilityDevelopment, members: []string{
+		"anthropic", "aws.bedrock", "azure.ai.inference", "azure.ai.openai", "cohere", "deepseek",
+		"gcp.gemini", "gcp.gen_ai", "gcp.vertex_ai", "groq", "ibm.watsonx.ai", "mistral_ai",
+		"openai", "perplexity", "x_ai",
+	}},
+	"gen_ai.system": {typ: TypeString, stability: stabilityDeprecated, members: []string{
+		"anthropic", "aws.bedrock", "az.ai.inference", "az.ai.openai", "cohere", "deepseek",
+		"gemini", "groq", "ibm.watsonx.ai", "mistral_ai", "openai", "perplexity", "vertex_ai", "xai",
+	}},
+	"gen_ai.operation.name": {typ: TypeString, stability: stabilityDevelopment, members: []string{
+		"chat", "create_agent", "embeddings", "execute_tool", "generate_content", "invoke_agent", "text_completion",
+	}},
+	"gen_ai.output.type": {typ: TypeString, stability: stabilityDevelopment, members: []string{
+		"image", "json", "speech", "text",
+	}},
+	"gen_ai.token.type": {typ: TypeString, stability: stabilityDevelopment, members: []string{
+		"input", "output",
+	}},
+	"gen_ai.conversation.id": {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.data_source.id":  {typ: TypeString, stability: stabilityDevelopment},
+
+	// Request.
+	"gen_ai.request.model":             {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.request.max_tokens":        {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.request.temperature":       {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.request.top_p":             {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.request.top_k":             {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.request.frequency_penalty": {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.request.presence_penalty":  {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.request.stop_sequences":    {typ: TypeStringSlice, stability: stabilityDevelopment},
+	"gen_ai.request.seed":              {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.request.choice.count":      {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.request.encoding_formats":  {typ: TypeStringSlice, stability: stabilityDevelopment},
+
+	// Response.
+	"gen_ai.response.id":             {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.response.model":          {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.response.finish_reasons": {typ: TypeStringSlice, stability: stabilityDevelopment},
+
+	// Usage. gen_ai.usage.total_tokens is not part of the conventions but is
+	// emitted by enough SDKs that it is treated as known.
+	"gen_ai.usage.input_tokens":      {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.usage.output_tokens":     {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.usage.total_tokens":      {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.usage.prompt_tokens":     {typ: TypeInt, stability: stabilityDeprecated},
+	"gen_ai.usage.completion_tokens": {typ: TypeInt, stability: stabilityDeprecated},
+
+	// Usage subtotals: cached input tokens, read from or written to the
+	// provider's prompt cache, and the reasoning tokens counted in the output.
+	"gen_ai.usage.cache_read.input_tokens":     {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.usage.cache_creation.input_tokens": {typ: TypeInt, stability: stabilityDevelopment},
+	"gen_ai.usage.reasoning.output_tokens":     {typ: TypeInt, stability: stabilityDevelopment},
+
+	// Content.
+	"gen_ai.input.messages":      {stability: stabilityDevelopment},
+	"gen_ai.output.messages":     {stability: stabilityDevelopment},
+	"gen_ai.system_instructions": {stability: stabilityDevelopment},
+	"gen_ai.prompt":              {typ: TypeString, stability: stabilityDeprecated},
+	"gen_ai.completion":          {typ: TypeString, stability: stabilityDeprecated},
+
+	// Agents and tools.
+	"gen_ai.agent.id":                   {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.agent.name":                 {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.agent.description":          {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.tool.name":                  {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.tool.call.id":               {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.tool.description":           {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.tool.type":                  {typ: TypeString, stability: stabilityDevelopment},
+	"gen_ai.embeddings.dimension.count": {typ: TypeInt, stability: stabilityDevelopment},
+
+	// Server timings, in seconds. They are not part of the conventions; they
+	// are named after the GenAI server metrics and written by the self-hosted
+	// inference server profiles.
+	"gen_ai.server.request.duration":      {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.server.time_to_first_token":   {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.server.time_per_output_token": {typ: TypeDouble, stability: stabilityDevelopment},
+
+	// Canonical model name. Not part of the conventions; written by model
+	// canonicalization.
+	"gen_ai.request.model.family": {typ: TypeString, stability: stabilityDevelopment},
+
+	// Estimated cost. Not part of the conventions; written by cost estimation.
+	"gen_ai.usage.cost":          {typ: TypeDouble, stability: stabilityDevelopment},
+	"gen_ai.usage.cost.currency": {typ: TypeString, stability: stabilityDevelopment},
+
+	// Vendor-specific.
+	"gen_ai.openai.request.service_tier":        {typ: TypeString, stability: stabilityDeprecated},
+	"gen_ai.openai.response.service_tier":       {typ: TypeString, stability: stabilityDeprecated},
+	"gen_ai.openai.response.system_fingerprint": {typ: TypeString, stability: stabilityDeprecated},
+}
+
+// outsideEnum reports whether val is a string outside the members of def,
+// when def is an enum.
+func (def attributeDef) outsideEnum(val string) bool {
+	return len(def.members) > 0 && !slices.Contains(def.members, val)
+}
+
+// semconvConverter returns the converter coercing values written to key to
+// its registry type, and whether key is a known attribute.
+func semconvConverter(key string) (converter, bool) {
+	def, ok := semconvRegistry[key]
+	if !ok || def.typ == "" {
+		return converter{}, ok
+	}
+	return converter{typ: def.typ, onFailure: OnFailureKeep}, true
+}
+
+// unknownKeyLabel returns key with its numeric segments replaced by "*", so
+// that indexed keys such as gen_ai.prompt.0.content share one label.
+func unknownKeyLabel(key string) string {
+	segments := strings.Split(key, ".")
+	for i, s := range segments {
+		if _, err := strconv.Atoi(s); err == nil {
+			segments[i] = "*"
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+func isGenAIKey(key string) bool {
+	return strings.HasPrefix(key, "gen_ai.")
+}
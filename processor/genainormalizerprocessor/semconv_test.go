@@ -0,0 +1,150 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+func TestSemconvTypeCoercion(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("anthropic.stop_reason", "end_turn")
+	sp.Attributes().PutStr("anthropic.input_tokens", "120")
+	sp.Attributes().PutStr("llm.request.temperature", "0.2")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	attrs := sp.Attributes()
+	if v, _ := attrs.Get("gen_ai.response.finish_reasons"); !reflect.DeepEqual(v.AsRaw(), []any{"end_turn"}) {
+		t.Fatalf("expected gen_ai.response.finish_reasons=[end_turn], got %v", v.AsRaw())
+	}
+	if v, _ := attrs.Get("gen_ai.usage.input_tokens"); v.Type() != pcommon.ValueTypeInt || v.Int() != 120 {
+		t.Fatalf("expected int gen_ai.usage.input_tokens=120, got %v", v.AsRaw())
+	}
+	if v, _ := attrs.Get("gen_ai.request.temperature"); v.Type() != pcommon.ValueTypeDouble || v.Double() != 0.2 {
+		t.Fatalf("expected double gen_ai.request.temperature=0.2, got %v", v.AsRaw())
+	}
+}
+
+func TestUnknownGenAIKeysCounted(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{
+		Logger:        zap.NewNop(),
+		MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Mappings = map[string]string{
+		"vendor.model":  "gen_ai.request.model",
+		"vendor.secret": "gen_ai.request.secret_sauce",
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("vendor.model", "gpt-4o")
+	sp.Attributes().PutStr("vendor.secret", "ketchup")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	// Unknown keys are still written.
+	assertHasStr(t, sp.Attributes(), "gen_ai.request.secret_sauce", "ketchup")
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect err: %v", err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("expected one metric, got %+v", rm.ScopeMetrics)
+	}
+	sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1 {
+		t.Fatalf("expected a single count of 1, got %+v", sum.DataPoints)
+	}
+	if key, _ := sum.DataPoints[0].Attributes.Value("key"); key.AsString() != "gen_ai.request.secret_sauce" {
+		t.Fatalf("unexpected key attribute %v", key.AsString())
+	}
+}
+
+func TestUnknownKeyLabel(t *testing.T) {
+	tests := map[string]string{
+		"gen_ai.request.secret_sauce":   "gen_ai.request.secret_sauce",
+		"gen_ai.prompt.0.content":       "gen_ai.prompt.*.content",
+		"gen_ai.choice.12.tool.3.name":  "gen_ai.choice.*.tool.*.name",
+		"gen_ai.request.v2.temperature": "gen_ai.request.v2.temperature",
+	}
+	for key, want := range tests {
+		if got := unknownKeyLabel(key); got != want {
+			t.Errorf("unknownKeyLabel(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestUnknownEnumValuesCounted(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{
+		Logger:        zap.NewNop(),
+		MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Mappings = map[string]string{"vendor.operation": "gen_ai.operation.name"}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	spans.AppendEmpty().Attributes().PutStr("vendor.operation", "chat")
+	spans.AppendEmpty().Attributes().PutStr("vendor.operation", "summarize")
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	assertHasStr(t, spans.At(1).Attributes(), "gen_ai.operation.name", "summarize")
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect err: %v", err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("expected one metric, got %+v", rm.ScopeMetrics)
+	}
+	sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1 {
+		t.Fatalf("expected a single count of 1, got %+v", sum.DataPoints)
+	}
+	dp := sum.DataPoints[0]
+	if key, _ := dp.Attributes.Value("key"); key.AsString() != "gen_ai.operation.name" {
+		t.Fatalf("unexpected key attribute %v", key.AsString())
+	}
+	if reason, _ := dp.Attributes.Value("reason"); reason.AsString() != "unknown_value" {
+		t.Fatalf("unexpected reason attribute %v", reason.AsString())
+	}
+}
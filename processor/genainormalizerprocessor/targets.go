@@ -0,0 +1,170 @@
+package genainormalizerprocessor
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Targets are the attribute maps a mapping may apply to.
+const (
+	TargetResource  = "resource"
+	TargetScope     = "scope"
+	TargetSpan      = "span"
+	TargetEvent     = "event"
+	TargetLink      = "link"
+	TargetLog       = "log"
+	TargetDataPoint = "datapoint"
+)
+
+var allTargets = []string{TargetResource, TargetScope, TargetSpan, TargetEvent, TargetLink, TargetLog, TargetDataPoint}
+
+// defaultTargets is used when neither the mapping nor the config lists targets.
+var defaultTargets = []string{TargetResource, TargetSpan, TargetEvent, TargetLink, TargetLog, TargetDataPoint}
+
+func validateTargets(targets []string) error {
+	for _, t := range targets {
+		known := false
+		for _, at := range allTargets {
+			known = known || t == at
+		}
+		if !known {
+			return fmt.Errorf("unknown target %q (supported: %s)", t, strings.Join(allTargets, ", "))
+		}
+	}
+	return nil
+}
+
+// ruleSet is the compiled mapping table for one target.
+type ruleSet struct {
+	// rules is the exact sources grouped by destination key in a
+	// deterministic order.
+	rules []destRule
+	// patterns holds the glob and regex sources, compiled once.
+	patterns []patternRule
+}
+
+// compileTargets compiles one ruleSet per target from the entries that apply
+// to it. Entries without their own targets use defaults.
+func compileTargets(defaults []string, priorities map[string][]string, tables ...[]mappingEntry) (map[string]ruleSet, error) {
+	sets := make(map[string]ruleSet, len(allTargets))
+	for _, target := range allTargets {
+		filtered := make([][]mappingEntry, len(tables))
+		for i, table := range tables {
+			for _, e := range table {
+				if e.appliesTo(target, defaults) {
+					filtered[i] = append(filtered[i], e)
+				}
+			}
+		}
+
+		var prio map[string][]string
+		if contains(defaults, target) {
+			prio = priorities
+		}
+
+		patterns, err := compilePatterns(filtered...)
+		if err != nil {
+			return nil, err
+		}
+		sets[target] = ruleSet{
+			rules:    compileRules(prio, filtered...),
+			patterns: patterns,
+		}
+	}
+	return sets, nil
+}
+
+func (e mappingEntry) appliesTo(target string, defaults []string) bool {
+	if len(e.targets) == 0 {
+		return contains(defaults, target)
+	}
+	return contains(e.targets, target)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGenAIAttributes reports whether attrs carries any gen_ai.* key, which is
+// how GenAI spans and log records are recognized.
+func hasGenAIAttributes(attrs pcommon.Map) bool {
+	found := false
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		found = isGenAIKey(k)
+		return !found
+	})
+	return found
+}
+
+// hoistDown copies the keys listed in hoist.down from the resource onto a
+// GenAI record that does not carry them yet.
+func (n *normalizer) hoistDown(resource, record pcommon.Map) {
+	if len(n.hoist.Down) == 0 || !hasGenAIAttributes(record) {
+		return
+	}
+	for _, key := range n.hoist.Down {
+		val, ok := resource.Get(key)
+		if !ok {
+			continue
+		}
+		if _, exists := record.Get(key); !exists {
+			val.CopyTo(record.PutEmpty(key))
+		}
+	}
+}
+
+// upHoister collects, for one resource, the values of the keys listed in
+// hoist.up across its GenAI records.
+type upHoister struct {
+	keys   []string
+	values map[string]pcommon.Value
+	mixed  map[string]bool
+}
+
+func (n *normalizer) newUpHoister() *upHoister {
+	if len(n.hoist.Up) == 0 {
+		return nil
+	}
+	return &upHoister{keys: n.hoist.Up, values: map[string]pcommon.Value{}, mixed: map[string]bool{}}
+}
+
+func (h *upHoister) observe(record pcommon.Map) {
+	if h == nil || !hasGenAIAttributes(record) {
+		return
+	}
+	for _, key := range h.keys {
+		val, ok := record.Get(key)
+		if !ok {
+			continue
+		}
+		if prev, seen := h.values[key]; seen {
+			h.mixed[key] = h.mixed[key] || prev.AsString() != val.AsString() || prev.Type() != val.Type()
+			continue
+		}
+		h.values[key] = val
+	}
+}
+
+// apply copies every key on which all observed GenAI records agree onto the
+// resource, unless the resource already has it.
+func (h *upHoister) apply(resource pcommon.Map) {
+	if h == nil {
+		return
+	}
+	for _, key := range h.keys {
+		val, ok := h.values[key]
+		if !ok || h.mixed[key] {
+			continue
+		}
+		if _, exists := resource.Get(key); !exists {
+			val.CopyTo(resource.PutEmpty(key))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func TestRuleTargets(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Mappings = map[string]string{"llm.provider": "gen_ai.provider.name"}
+	cfg.Rules = []MappingRule{
+		{Source: "llm.model", Destination: "gen_ai.request.model", Targets: []string{TargetScope}},
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("llm.provider", "langchain")
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().Attributes().PutStr("llm.model", "gpt-4o")
+	sp := ss.Spans().AppendEmpty()
+	sp.Attributes().PutStr("llm.model", "gpt-4o")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, rs.Resource().Attributes(), "gen_ai.provider.name", "langchain")
+	assertHasStr(t, ss.Scope().Attributes(), "gen_ai.request.model", "gpt-4o")
+	if _, ok := sp.Attributes().Get("gen_ai.request.model"); ok {
+		t.Fatalf("expected scope-only rule not to apply to spans")
+	}
+}
+
+func TestLinkTargetOptOut(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Targets = []string{TargetSpan, TargetEvent}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("llm.model", "gpt-4o")
+	link := sp.Links().AppendEmpty()
+	link.Attributes().PutStr("llm.model", "gpt-4o")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, sp.Attributes(), "gen_ai.request.model", "gpt-4o")
+	if _, ok := link.Attributes().Get("gen_ai.request.model"); ok {
+		t.Fatalf("expected link attributes to be left alone without the link target")
+	}
+}
+
+func TestHoist(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Hoist = HoistConfig{
+		Down: []string{"gen_ai.provider.name"},
+		Up:   []string{"gen_ai.request.model", "gen_ai.operation.name"},
+	}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("llm.provider", "openai")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+
+	chat := spans.AppendEmpty()
+	chat.Attributes().PutStr("llm.model", "gpt-4o")
+	chat.Attributes().PutStr("llm.operation", "chat")
+	embed := spans.AppendEmpty()
+	embed.Attributes().PutStr("llm.model", "gpt-4o")
+	embed.Attributes().PutStr("llm.operation", "embeddings")
+	plain := spans.AppendEmpty()
+	plain.Attributes().PutStr("http.method", "GET")
+
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	assertHasStr(t, chat.Attributes(), "gen_ai.provider.name", "openai")
+	assertHasStr(t, embed.Attributes(), "gen_ai.provider.name", "openai")
+	if _, ok := plain.Attributes().Get("gen_ai.provider.name"); ok {
+		t.Fatalf("expected non-GenAI span to be left alone")
+	}
+
+	res := rs.Resource().Attributes()
+	assertHasStr(t, res, "gen_ai.request.model", "gpt-4o")
+	if _, ok := res.Get("gen_ai.operation.name"); ok {
+		t.Fatalf("expected disagreeing gen_ai.operation.name not to be hoisted")
+	}
+}
+
+func TestUnknownTarget(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Targets = []string{"span", "trace"}
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for unknown target")
+	}
+}
@@ -0,0 +1,78 @@
+package genainormalizerprocessor
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// OpenLLMetry (Traceloop) records chat messages as pre-standard, indexed
+// gen_ai.* span attributes:
+//
+//	gen_ai.prompt.0.role = "user"
+//	gen_ai.prompt.0.content = "hello"
+//	gen_ai.completion.0.finish_reason = "stop"
+//	gen_ai.completion.0.tool_calls.0.name = "search"
+//
+// See: https://github.com/traceloop/openllmetry
+const (
+	traceloopPromptPrefix     = "gen_ai.prompt."
+	traceloopCompletionPrefix = "gen_ai.completion."
+)
+
+// traceloopMappings are the usage keys OpenLLMetry emits next to its
+// messages.
+var traceloopMappings = map[string]string{
+	"llm.usage.total_tokens":         "gen_ai.usage.total_tokens",
+	"gen_ai.usage.prompt_tokens":     "gen_ai.usage.input_tokens",
+	"gen_ai.usage.completion_tokens": "gen_ai.usage.output_tokens",
+
+	"gen_ai.usage.cache_read_input_tokens":     "gen_ai.usage.cache_read.input_tokens",
+	"gen_ai.usage.cache_creation_input_tokens": "gen_ai.usage.cache_creation.input_tokens",
+	"llm.usage.reasoning_tokens":               "gen_ai.usage.reasoning.output_tokens",
+}
+
+// traceloopMessages reassembles the OpenLLMetry prompt and completion
+// messages of attrs and returns the indexed keys they were built from.
+func traceloopMessages(attrs pcommon.Map) (inputs, outputs []genaiMessage, keys []string) {
+	inputs, inKeys := collectMessages(attrs, traceloopPromptPrefix, traceloopField)
+	outputs, outKeys := collectMessages(attrs, traceloopCompletionPrefix, traceloopField)
+	return inputs, outputs, append(inKeys, outKeys...)
+}
+
+func traceloopField(m *genaiMessage, path string, v pcommon.Value) bool {
+	switch path {
+	case "role":
+		m.role = v.AsString()
+		return true
+	case "content":
+		m.content = v.AsString()
+		return true
+	case "tool_call_id":
+		m.toolCallID = v.AsString()
+		return true
+	case "finish_reason":
+		m.finishReason = v.AsString()
+		return true
+	}
+
+	// tool_calls.N.{id,name,arguments}
+	if rest, ok := strings.CutPrefix(path, "tool_calls."); ok {
+		idx, field, ok := splitIndex(rest)
+		if !ok {
+			return false
+		}
+		switch field {
+		case "id":
+			m.toolCallAt(idx).id = v.AsString()
+		case "name":
+			m.toolCallAt(idx).name = v.AsString()
+		case "arguments":
+			m.toolCallAt(idx).arguments = v.AsString()
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package genainormalizerprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+)
+
+func traceloopSpan() ptrace.Traces {
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	attrs := sp.Attributes()
+	attrs.PutStr("gen_ai.prompt.0.role", "system")
+	attrs.PutStr("gen_ai.prompt.0.content", "be brief")
+	attrs.PutStr("gen_ai.prompt.1.role", "user")
+	attrs.PutStr("gen_ai.prompt.1.content", "weather?")
+	attrs.PutStr("gen_ai.completion.0.role", "assistant")
+	attrs.PutStr("gen_ai.completion.0.finish_reason", "tool_calls")
+	attrs.PutStr("gen_ai.completion.0.tool_calls.0.id", "call_1")
+	attrs.PutStr("gen_ai.completion.0.tool_calls.0.name", "weather")
+	attrs.PutStr("gen_ai.completion.0.tool_calls.0.arguments", `{"city":"Paris"}`)
+	attrs.PutStr("gen_ai.completion.1.role", "assistant")
+	attrs.PutStr("gen_ai.completion.1.content", "sunny")
+	attrs.PutStr("gen_ai.completion.1.finish_reason", "stop")
+	attrs.PutInt("gen_ai.usage.prompt_tokens", 12)
+	attrs.PutStr("llm.usage.total_tokens", "20")
+	return td
+}
+
+func TestTraceloopMessageEvents(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.EnableDefaults = false
+	cfg.Profiles = []string{ProfileTraceloop}
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := traceloopSpan()
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	sp := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+
+	var names []string
+	for i := 0; i < sp.Events().Len(); i++ {
+		names = append(names, sp.Events().At(i).Name())
+	}
+	want := []string{"gen_ai.system.message", "gen_ai.user.message", "gen_ai.choice", "gen_ai.choice"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected events %v", names)
+	}
+	assertHasStr(t, sp.Events().At(2).Attributes(), "finish_reason", "tool_calls")
+
+	reasons, ok := sp.Attributes().Get("gen_ai.response.finish_reasons")
+	if !ok || !reflect.DeepEqual(reasons.AsRaw(), []any{"tool_calls", "stop"}) {
+		t.Fatalf("unexpected finish reasons %v", reasons.AsRaw())
+	}
+	if v, _ := sp.Attributes().Get("gen_ai.usage.total_tokens"); v.Int() != 20 {
+		t.Fatalf("expected total tokens 20, got %v", v.AsRaw())
+	}
+	if v, _ := sp.Attributes().Get("gen_ai.usage.input_tokens"); v.Int() != 12 {
+		t.Fatalf("expected input tokens 12, got %v", v.AsRaw())
+	}
+	if _, ok := sp.Attributes().Get("gen_ai.input.messages"); ok {
+		t.Fatalf("did not expect message attributes in events mode")
+	}
+}
+
+func TestTraceloopMessageAttributes(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileTraceloop}
+	cfg.MessageOutput = MessageOutputAttributes
+	cfg.DropOriginal = true
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := traceloopSpan()
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+	sp := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+
+	if sp.Events().Len() != 0 {
+		t.Fatalf("expected no events in attributes mode, got %d", sp.Events().Len())
+	}
+	if _, ok := sp.Attributes().Get("gen_ai.prompt.0.content"); ok {
+		t.Fatalf("expected indexed prompt keys to be dropped")
+	}
+
+	in, _ := sp.Attributes().Get("gen_ai.input.messages")
+	var inputs []map[string]any
+	if err := json.Unmarshal([]byte(in.Str()), &inputs); err != nil {
+		t.Fatalf("invalid input messages %q: %v", in.Str(), err)
+	}
+	wantIn := []map[string]any{
+		{"role": "system", "parts": []any{map[string]any{"type": "text", "content": "be brief"}}},
+		{"role": "user", "parts": []any{map[string]any{"type": "text", "content": "weather?"}}},
+	}
+	if !reflect.DeepEqual(inputs, wantIn) {
+		t.Fatalf("unexpected input messages %v", inputs)
+	}
+
+	out, _ := sp.Attributes().Get("gen_ai.output.messages")
+	var outputs []map[string]any
+	if err := json.Unmarshal([]byte(out.Str()), &outputs); err != nil {
+		t.Fatalf("invalid output messages %q: %v", out.Str(), err)
+	}
+	wantOut := []map[string]any{
+		{"role": "assistant", "finish_reason": "tool_calls", "parts": []any{map[string]any{
+			"type": "tool_call", "id": "call_1", "name": "weather", "arguments": map[string]any{"city": "Paris"},
+		}}},
+		{"role": "assistant", "finish_reason": "stop", "parts": []any{map[string]any{"type": "text", "content": "sunny"}}},
+	}
+	if !reflect.DeepEqual(outputs, wantOut) {
+		t.Fatalf("unexpected output messages %v", outputs)
+	}
+}
+
+func TestUnknownMessageOutput(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.MessageOutput = "stdout"
+
+	if _, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop()); err == nil {
+		t.Fatalf("expected error for unknown message_output")
+	}
+}
+
+func TestTraceloopUnknownToolCallFields(t *testing.T) {
+	settings := processor.CreateSettings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}}
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileTraceloop}
+	cfg.MessageOutput = MessageOutputAttributes
+
+	p, err := newTracesProcessor(context.Background(), settings, cfg, consumertest.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	td := ptrace.NewTraces()
+	sp := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	sp.Attributes().PutStr("gen_ai.completion.0.role", "assistant")
+	sp.Attributes().PutStr("gen_ai.completion.0.tool_calls.0.id", "call_1")
+	sp.Attributes().PutStr("gen_ai.completion.0.tool_calls.0.name", "weather")
+	sp.Attributes().PutStr("gen_ai.completion.0.tool_calls.1.vendor_index", "1")
+	if err := p.ConsumeTraces(context.Background(), td); err != nil {
+		t.Fatalf("consume err: %v", err)
+	}
+
+	out, _ := sp.Attributes().Get("gen_ai.output.messages")
+	var outputs []map[string]any
+	if err := json.Unmarshal([]byte(out.Str()), &outputs); err != nil {
+		t.Fatalf("invalid output messages %q: %v", out.Str(), err)
+	}
+	want := []map[string]any{
+		{"role": "assistant", "finish_reason": "unknown", "parts": []any{map[string]any{"type": "tool_call", "id": "call_1", "name": "weather"}}},
+	}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Fatalf("unexpected output messages %v", outputs)
+	}
+}
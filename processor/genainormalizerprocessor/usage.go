@@ -0,0 +1,64 @@
+package genainormalizerprocessor
+
+import "go.opentelemetry.io/collector/pdata/pcommon"
+
+// Token usage keys.
+const (
+	inputTokensAttr         = "gen_ai.usage.input_tokens"
+	outputTokensAttr        = "gen_ai.usage.output_tokens"
+	totalTokensAttr         = "gen_ai.usage.total_tokens"
+	cacheReadTokensAttr     = "gen_ai.usage.cache_read.input_tokens"
+	cacheCreationTokensAttr = "gen_ai.usage.cache_creation.input_tokens"
+	reasoningTokensAttr     = "gen_ai.usage.reasoning.output_tokens"
+)
+
+// usageInconsistentAttr is set to true on records whose token counts
+// contradict each other.
+const usageInconsistentAttr = "genai_normalizer.usage_inconsistent"
+
+// reconcileUsage fills gen_ai.usage.total_tokens from the input and output
+// counts, or the output count from the total and input counts, and flags the
+// record when its counts are inconsistent: a negative count, a total that is
+// neither input + output nor input + output + cached input (as reported by
+// Anthropic, whose input count excludes cached tokens), or more reasoning
+// tokens than output tokens.
+func reconcileUsage(attrs pcommon.Map) {
+	input, hasInput := usageCount(attrs, inputTokensAttr)
+	output, hasOutput := usageCount(attrs, outputTokensAttr)
+	total, hasTotal := usageCount(attrs, totalTokensAttr)
+	cacheRead, _ := usageCount(attrs, cacheReadTokensAttr)
+	cacheCreation, _ := usageCount(attrs, cacheCreationTokensAttr)
+	reasoning, hasReasoning := usageCount(attrs, reasoningTokensAttr)
+
+	inconsistent := input < 0 || output < 0 || total < 0 || cacheRead < 0 || cacheCreation < 0 || reasoning < 0
+	switch {
+	case hasInput && hasOutput && !hasTotal:
+		attrs.PutInt(totalTokensAttr, input+output)
+	case hasInput && !hasOutput && hasTotal:
+		if total >= input {
+			output, hasOutput = total-input, true
+			attrs.PutInt(outputTokensAttr, output)
+		} else {
+			inconsistent = true
+		}
+	case hasInput && hasOutput && hasTotal:
+		if total != input+output && total != input+output+cacheRead+cacheCreation {
+			inconsistent = true
+		}
+	}
+	if hasReasoning && hasOutput && reasoning > output {
+		inconsistent = true
+	}
+	if inconsistent {
+		attrs.PutBool(usageInconsistentAttr, true)
+	}
+}
+
+// usageCount returns the integer count stored under key.
+func usageCount(attrs pcommon.Map, key string) (int64, bool) {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return 0, false
+	}
+	return asInt(v)
+}
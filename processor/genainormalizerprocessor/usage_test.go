@@ -0,0 +1,115 @@
+package genainormalizerprocessor
+
+import (
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestReconcileUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		in   map[string]any
+		want map[string]any
+	}{
+		{
+			name: "fills total",
+			in:   map[string]any{inputTokensAttr: 10, outputTokensAttr: 5},
+			want: map[string]any{inputTokensAttr: int64(10), outputTokensAttr: int64(5), totalTokensAttr: int64(15)},
+		},
+		{
+			name: "fills output",
+			in:   map[string]any{inputTokensAttr: 10, totalTokensAttr: 25},
+			want: map[string]any{inputTokensAttr: int64(10), outputTokensAttr: int64(15), totalTokensAttr: int64(25)},
+		},
+		{
+			name: "total below input",
+			in:   map[string]any{inputTokensAttr: 10, totalTokensAttr: 5},
+			want: map[string]any{inputTokensAttr: int64(10), totalTokensAttr: int64(5), usageInconsistentAttr: true},
+		},
+		{
+			name: "consistent",
+			in:   map[string]any{inputTokensAttr: 10, outputTokensAttr: 5, totalTokensAttr: 15},
+			want: map[string]any{inputTokensAttr: int64(10), outputTokensAttr: int64(5), totalTokensAttr: int64(15)},
+		},
+		{
+			name: "total including cached input",
+			in:   map[string]any{inputTokensAttr: 10, outputTokensAttr: 5, cacheReadTokensAttr: 100, cacheCreationTokensAttr: 20, totalTokensAttr: 135},
+			want: map[string]any{inputTokensAttr: int64(10), outputTokensAttr: int64(5), cacheReadTokensAttr: int64(100), cacheCreationTokensAttr: int64(20), totalTokensAttr: int64(135)},
+		},
+		{
+			name: "wrong total",
+			in:   map[string]any{inputTokensAttr: 10, outputTokensAttr: 5, totalTokensAttr: 42},
+			want: map[string]any{inputTokensAttr: int64(10), outputTokensAttr: int64(5), totalTokensAttr: int64(42), usageInconsistentAttr: true},
+		},
+		{
+			name: "reasoning above output",
+			in:   map[string]any{outputTokensAttr: 5, reasoningTokensAttr: 8},
+			want: map[string]any{outputTokensAttr: int64(5), reasoningTokensAttr: int64(8), usageInconsistentAttr: true},
+		},
+		{
+			name: "negative count",
+			in:   map[string]any{inputTokensAttr: -1},
+			want: map[string]any{inputTokensAttr: int64(-1), usageInconsistentAttr: true},
+		},
+		{
+			name: "no usage",
+			in:   map[string]any{"gen_ai.request.model": "gpt-4o"},
+			want: map[string]any{"gen_ai.request.model": "gpt-4o"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			if err := attrs.FromRaw(tt.in); err != nil {
+				t.Fatalf("invalid attributes: %v", err)
+			}
+			reconcileUsage(attrs)
+			if got := attrs.AsRaw(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveUsage(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Profiles = []string{ProfileAnthropic, ProfileOpenAI}
+	cfg.DeriveUsage = true
+
+	attrs := consumeSpan(t, cfg, map[string]any{
+		"anthropic.input_tokens":                "12",
+		"anthropic.output_tokens":               30,
+		"anthropic.cache_read_input_tokens":     2048,
+		"anthropic.cache_creation_input_tokens": 0,
+	})
+	want := map[string]int64{
+		inputTokensAttr:         12,
+		outputTokensAttr:        30,
+		totalTokensAttr:         42,
+		cacheReadTokensAttr:     2048,
+		cacheCreationTokensAttr: 0,
+	}
+	for key, n := range want {
+		if v, ok := attrs.Get(key); !ok || v.Type() != pcommon.ValueTypeInt || v.Int() != n {
+			t.Fatalf("expected %s=%d, got %v", key, n, attrs.AsRaw())
+		}
+	}
+
+	attrs = consumeSpan(t, cfg, map[string]any{
+		"openai.prompt_tokens":                              100,
+		"openai.total_tokens":                               150,
+		"openai.prompt_tokens_details.cached_tokens":        64,
+		"openai.completion_tokens_details.reasoning_tokens": 20,
+	})
+	if v, ok := attrs.Get(outputTokensAttr); !ok || v.Int() != 50 {
+		t.Fatalf("expected derived output tokens, got %v", attrs.AsRaw())
+	}
+	if v, ok := attrs.Get(reasoningTokensAttr); !ok || v.Int() != 20 {
+		t.Fatalf("expected reasoning tokens, got %v", attrs.AsRaw())
+	}
+	if _, ok := attrs.Get(usageInconsistentAttr); ok {
+		t.Fatalf("expected consistent usage, got %v", attrs.AsRaw())
+	}
+}